@@ -0,0 +1,273 @@
+// Package config assembles Dependagraph's runtime configuration from four
+// layers, each overriding the one before it: built-in defaults, an
+// optional YAML file, environment variables, and CLI flags. A layer only
+// overrides the keys it actually sets, so operators can, for example,
+// keep credentials in the environment while everything else lives in a
+// checked-in YAML file.
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Options is the fully resolved, validated configuration for a
+// Dependagraph run.
+type Options struct {
+	Seeds       []string `yaml:"seeds"`
+	Coalesce    bool     `yaml:"coalesce"`
+	Concurrency int      `yaml:"concurrency"`
+	// Serve, if non-empty, is the address the HTTP query API listens on
+	// (e.g. ":8080"), run alongside any crawling this invocation does.
+	Serve   string         `yaml:"serve"`
+	Source  SourceOptions  `yaml:"source"`
+	Storage StorageOptions `yaml:"storage"`
+}
+
+// SourceOptions configures the DependencySource used to crawl a forge.
+type SourceOptions struct {
+	Kind             string `yaml:"kind"`
+	APIURL           string `yaml:"api_url"`
+	Token            string `yaml:"token"`
+	RateLimitPerHour int    `yaml:"rate_limit_per_hour"`
+}
+
+// StorageOptions configures the graph storage backend.
+type StorageOptions struct {
+	Backend  string `yaml:"backend"`
+	DSN      string `yaml:"dsn"`
+	URI      string `yaml:"uri"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+}
+
+func defaults() Options {
+	return Options{
+		Concurrency: 4,
+		Source:      SourceOptions{Kind: "github"},
+		Storage:     StorageOptions{Backend: "neo4j"},
+	}
+}
+
+// Load assembles Options by layering defaults, an optional YAML config
+// file (-config), environment variables and CLI flags, in that order.
+// args should be the process's arguments excluding the program name
+// (typically os.Args[1:]).
+func Load(args []string) (Options, error) {
+	opts := defaults()
+
+	fs := flag.NewFlagSet("dependagraph", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Path to a YAML config file.")
+	repository := fs.String("repository", "", "The repo to seed the graph with.\nMust be in the form of org/repo (e.g, offset46/Dependagraph)")
+	coalesce := fs.Bool("coalesce", false, "This enables unlimited crawling mode.\nAfter seeding, grab a leaf node and run again with the leaf as the new seed.")
+	concurrency := fs.Int("concurrency", 0, "Number of repositories to crawl concurrently in coalesce mode.")
+	sourceKind := fs.String("source", "", "The forge to crawl: github, gitlab or gitea.")
+	apiURL := fs.String("api-url", "", "Base API URL for the forge, for self-hosted instances (e.g. https://git.example.org).")
+	storageBackend := fs.String("storage", "", "The graph storage backend: neo4j, sqlite or memory.")
+	storageDSN := fs.String("storage-dsn", "", "Data source name for the storage backend (ignored by neo4j, which uses -config or NEO4J_* env vars).")
+	serve := fs.String("serve", "", "Address to serve the HTTP query API on (e.g. :8080). Runs alongside any crawling this invocation does.")
+
+	if err := fs.Parse(args); err != nil {
+		return Options{}, fmt.Errorf("config: parsing flags: %w", err)
+	}
+
+	if *configPath != "" {
+		if err := applyYAML(&opts, *configPath); err != nil {
+			return Options{}, err
+		}
+	}
+
+	applyEnv(&opts)
+
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	if explicit["repository"] {
+		opts.Seeds = []string{*repository}
+	}
+	if explicit["coalesce"] {
+		opts.Coalesce = *coalesce
+	}
+	if explicit["concurrency"] {
+		opts.Concurrency = *concurrency
+	}
+	if explicit["source"] {
+		opts.Source.Kind = *sourceKind
+	}
+	if explicit["api-url"] {
+		opts.Source.APIURL = *apiURL
+	}
+	if explicit["storage"] {
+		opts.Storage.Backend = *storageBackend
+	}
+	if explicit["storage-dsn"] {
+		opts.Storage.DSN = *storageDSN
+	}
+	if explicit["serve"] {
+		opts.Serve = *serve
+	}
+
+	if err := validate(opts); err != nil {
+		return Options{}, err
+	}
+
+	return opts, nil
+}
+
+// LoadForReport assembles the storage half of Options for the report
+// subcommand, which reads an existing graph rather than crawling one, so
+// it validates storage only and doesn't require seeds or a source. It
+// also parses the subcommand's own flags (currently just -outdated) so
+// callers don't need a second, conflicting flag.FlagSet; the reported
+// bool is whether -outdated was passed.
+func LoadForReport(args []string) (Options, bool, error) {
+	opts := defaults()
+
+	fs := flag.NewFlagSet("dependagraph report", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Path to a YAML config file.")
+	storageBackend := fs.String("storage", "", "The graph storage backend: neo4j, sqlite or memory.")
+	storageDSN := fs.String("storage-dsn", "", "Data source name for the storage backend (ignored by neo4j, which uses -config or NEO4J_* env vars).")
+	outdated := fs.Bool("outdated", false, "List dependencies whose declared requirement no longer permits their latest release.")
+
+	if err := fs.Parse(args); err != nil {
+		return Options{}, false, fmt.Errorf("config: parsing flags: %w", err)
+	}
+
+	if *configPath != "" {
+		if err := applyYAML(&opts, *configPath); err != nil {
+			return Options{}, false, err
+		}
+	}
+
+	applyEnv(&opts)
+
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	if explicit["storage"] {
+		opts.Storage.Backend = *storageBackend
+	}
+	if explicit["storage-dsn"] {
+		opts.Storage.DSN = *storageDSN
+	}
+
+	if err := validateStorage(opts); err != nil {
+		return Options{}, false, err
+	}
+
+	return opts, *outdated, nil
+}
+
+func applyYAML(opts *Options, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("config: reading %s: %w", path, err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(opts); err != nil {
+		return fmt.Errorf("config: parsing %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func applyEnv(opts *Options) {
+	if v := os.Getenv("NEO4J_URI"); v != "" {
+		opts.Storage.URI = v
+	}
+	if v := os.Getenv("NEO4J_USR"); v != "" {
+		opts.Storage.User = v
+	}
+	if v := os.Getenv("NEO4J_PWD"); v != "" {
+		opts.Storage.Password = v
+	}
+	if v := os.Getenv("GITHUB_API_SECRET"); v != "" {
+		opts.Source.Token = v
+	}
+	if v := os.Getenv("DEPENDAGRAPH_SOURCE"); v != "" {
+		opts.Source.Kind = v
+	}
+	if v := os.Getenv("DEPENDAGRAPH_API_URL"); v != "" {
+		opts.Source.APIURL = v
+	}
+	if v := os.Getenv("DEPENDAGRAPH_STORAGE"); v != "" {
+		opts.Storage.Backend = v
+	}
+	if v := os.Getenv("DEPENDAGRAPH_STORAGE_DSN"); v != "" {
+		opts.Storage.DSN = v
+	}
+	if v := os.Getenv("DEPENDAGRAPH_CONCURRENCY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			opts.Concurrency = n
+		}
+	}
+	if v := os.Getenv("DEPENDAGRAPH_SERVE"); v != "" {
+		opts.Serve = v
+	}
+}
+
+// validate checks that opts is complete enough to run, naming both the
+// missing key and where it can be set when it isn't.
+func validate(opts Options) error {
+	if len(opts.Seeds) == 0 && opts.Serve == "" {
+		return fmt.Errorf("config: seeds is empty (set seeds in YAML or pass -repository, or pass -serve to run the query API only)")
+	}
+
+	if opts.Concurrency < 1 {
+		return fmt.Errorf("config: concurrency must be at least 1 (set concurrency in YAML, DEPENDAGRAPH_CONCURRENCY, or -concurrency)")
+	}
+
+	if err := validateStorage(opts); err != nil {
+		return err
+	}
+
+	if len(opts.Seeds) == 0 {
+		// Nothing to crawl (-serve was passed on its own): the source
+		// config that follows only matters once there's a seed to fetch.
+		return nil
+	}
+
+	switch opts.Source.Kind {
+	case "github":
+		if opts.Source.Token == "" {
+			return fmt.Errorf("config: source.token is required for the github source (set source.token in YAML or GITHUB_API_SECRET)")
+		}
+	case "gitlab", "gitea":
+		// Public repositories can be crawled without a token.
+	default:
+		return fmt.Errorf("config: source.kind %q is not one of github, gitlab, gitea (set source.kind in YAML, DEPENDAGRAPH_SOURCE, or -source)", opts.Source.Kind)
+	}
+
+	return nil
+}
+
+// validateStorage checks only the storage half of opts, so callers that
+// don't crawl (such as the report subcommand) can validate without also
+// requiring seeds and a source.
+func validateStorage(opts Options) error {
+	switch opts.Storage.Backend {
+	case "neo4j":
+		if opts.Storage.URI == "" {
+			return fmt.Errorf("config: storage.uri is required for the neo4j backend (set storage.uri in YAML or NEO4J_URI)")
+		}
+		if opts.Storage.User == "" {
+			return fmt.Errorf("config: storage.user is required for the neo4j backend (set storage.user in YAML or NEO4J_USR)")
+		}
+		if opts.Storage.Password == "" {
+			return fmt.Errorf("config: storage.password is required for the neo4j backend (set storage.password in YAML or NEO4J_PWD)")
+		}
+	case "sqlite", "memory":
+		// No credentials required.
+	default:
+		return fmt.Errorf("config: storage.backend %q is not one of neo4j, sqlite, memory (set storage.backend in YAML, DEPENDAGRAPH_STORAGE, or -storage)", opts.Storage.Backend)
+	}
+
+	return nil
+}
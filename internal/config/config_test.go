@@ -0,0 +1,169 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadDefaults(t *testing.T) {
+	t.Setenv("GITHUB_API_SECRET", "token")
+	t.Setenv("NEO4J_URI", "bolt://localhost:7687")
+	t.Setenv("NEO4J_USR", "neo4j")
+	t.Setenv("NEO4J_PWD", "pass")
+
+	opts, err := Load([]string{"-repository", "org/repo"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if opts.Concurrency != 4 {
+		t.Errorf("Concurrency = %d, want default 4", opts.Concurrency)
+	}
+	if opts.Source.Kind != "github" {
+		t.Errorf("Source.Kind = %q, want default github", opts.Source.Kind)
+	}
+	if opts.Storage.Backend != "neo4j" {
+		t.Errorf("Storage.Backend = %q, want default neo4j", opts.Storage.Backend)
+	}
+	if got := opts.Seeds; len(got) != 1 || got[0] != "org/repo" {
+		t.Errorf("Seeds = %v, want [org/repo]", got)
+	}
+}
+
+func TestLoadFlagsOverrideYAML(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	yaml := `
+seeds: ["yaml/seed"]
+concurrency: 2
+source:
+  kind: gitlab
+storage:
+  backend: sqlite
+  dsn: yaml.db
+`
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := Load([]string{"-config", path, "-concurrency", "8", "-storage-dsn", "flag.db"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if opts.Concurrency != 8 {
+		t.Errorf("Concurrency = %d, want flag override 8", opts.Concurrency)
+	}
+	if opts.Source.Kind != "gitlab" {
+		t.Errorf("Source.Kind = %q, want yaml value gitlab (no flag set)", opts.Source.Kind)
+	}
+	if opts.Storage.DSN != "flag.db" {
+		t.Errorf("Storage.DSN = %q, want flag override flag.db", opts.Storage.DSN)
+	}
+	if got := opts.Seeds; len(got) != 1 || got[0] != "yaml/seed" {
+		t.Errorf("Seeds = %v, want yaml value [yaml/seed] (no -repository flag)", got)
+	}
+}
+
+func TestLoadEnvOverridesYAMLButNotFlags(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("storage:\n  backend: sqlite\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("DEPENDAGRAPH_STORAGE", "memory")
+	t.Setenv("GITHUB_API_SECRET", "token")
+
+	opts, err := Load([]string{"-config", path, "-repository", "org/repo"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if opts.Storage.Backend != "memory" {
+		t.Errorf("Storage.Backend = %q, want env override memory", opts.Storage.Backend)
+	}
+
+	opts, err = Load([]string{"-config", path, "-repository", "org/repo", "-storage", "sqlite"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if opts.Storage.Backend != "sqlite" {
+		t.Errorf("Storage.Backend = %q, want flag override sqlite even with env set", opts.Storage.Backend)
+	}
+}
+
+func TestLoadValidatesMissingSeeds(t *testing.T) {
+	if _, err := Load(nil); err == nil {
+		t.Fatal("Load with no seeds and no -serve: want error, got nil")
+	}
+}
+
+func TestLoadAllowsServeWithoutSeeds(t *testing.T) {
+	opts, err := Load([]string{"-serve", ":8080", "-storage", "memory"})
+	if err != nil {
+		t.Fatalf("Load with -serve and no seeds: %v", err)
+	}
+	if opts.Serve != ":8080" {
+		t.Errorf("Serve = %q, want :8080", opts.Serve)
+	}
+}
+
+func TestLoadValidatesConcurrency(t *testing.T) {
+	if _, err := Load([]string{"-repository", "org/repo", "-concurrency", "0"}); err == nil {
+		t.Fatal("Load with -concurrency 0: want error, got nil")
+	}
+}
+
+func TestLoadValidatesGithubRequiresToken(t *testing.T) {
+	if _, err := Load([]string{"-repository", "org/repo", "-storage", "memory"}); err == nil {
+		t.Fatal("Load with github source and no token: want error, got nil")
+	}
+}
+
+func TestLoadAllowsGitlabWithoutToken(t *testing.T) {
+	if _, err := Load([]string{"-repository", "org/repo", "-source", "gitlab", "-storage", "memory"}); err != nil {
+		t.Fatalf("Load with gitlab source and no token: %v", err)
+	}
+}
+
+func TestLoadValidatesUnknownSourceKind(t *testing.T) {
+	if _, err := Load([]string{"-repository", "org/repo", "-source", "bogus", "-storage", "memory"}); err == nil {
+		t.Fatal("Load with unknown source kind: want error, got nil")
+	}
+}
+
+func TestLoadValidatesNeo4jRequiresCredentials(t *testing.T) {
+	if _, err := Load([]string{"-serve", ":8080", "-storage", "neo4j"}); err == nil {
+		t.Fatal("Load with neo4j storage and no credentials: want error, got nil")
+	}
+}
+
+func TestLoadValidatesUnknownStorageBackend(t *testing.T) {
+	if _, err := Load([]string{"-serve", ":8080", "-storage", "bogus"}); err == nil {
+		t.Fatal("Load with unknown storage backend: want error, got nil")
+	}
+}
+
+func TestLoadForReportParsesOutdatedFlag(t *testing.T) {
+	_, outdated, err := LoadForReport([]string{"-storage", "memory", "-outdated"})
+	if err != nil {
+		t.Fatalf("LoadForReport: %v", err)
+	}
+	if !outdated {
+		t.Error("outdated = false, want true")
+	}
+}
+
+func TestLoadForReportDoesNotRequireSeedsOrSource(t *testing.T) {
+	opts, outdated, err := LoadForReport([]string{"-storage", "sqlite", "-storage-dsn", "report.db"})
+	if err != nil {
+		t.Fatalf("LoadForReport: %v", err)
+	}
+	if outdated {
+		t.Error("outdated = true, want false (flag not passed)")
+	}
+	if opts.Storage.DSN != "report.db" {
+		t.Errorf("Storage.DSN = %q, want report.db", opts.Storage.DSN)
+	}
+}
@@ -0,0 +1,167 @@
+// Package crawler implements the bounded worker pool that walks the
+// dependency graph in coalesce mode: pulling untargeted repositories from
+// storage, fetching their dependency window from a DependencySource, and
+// writing the result back, while respecting the source's rate limits and
+// shutting down gracefully when asked to stop.
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/trinitroglycerin/dependagraph/internal/source"
+)
+
+// Store is the subset of storage operations the crawler needs: a way to
+// pull the next batch of work and a way to persist a fetched window.
+type Store interface {
+	// NextBatch returns up to n untargeted repository refs, marking them
+	// in-flight so that a concurrent call doesn't return the same ref
+	// twice.
+	NextBatch(ctx context.Context, n int) ([]string, error)
+	// Release clears the in-flight flag NextBatch set on refs without
+	// marking them targeted, so a future NextBatch call hands them out
+	// again. dispatch uses this to give back any batch remainder it
+	// couldn't hand to a worker before shutting down.
+	Release(ctx context.Context, refs []string) error
+	SaveWindow(ctx context.Context, ref string, dependencies, dependents []source.Repository) error
+}
+
+// Crawler owns a fixed-size worker pool that fetches dependency windows
+// for queued repositories. Rate limiting against the underlying forge is
+// the DependencySource's responsibility, not the Crawler's: the pool only
+// bounds how many fetches run concurrently.
+type Crawler struct {
+	src     source.DependencySource
+	store   Store
+	workers int
+}
+
+// New builds a Crawler with the given worker count.
+func New(src source.DependencySource, store Store, workers int) *Crawler {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &Crawler{src: src, store: store, workers: workers}
+}
+
+// Seed resolves ref against the source to validate it and canonicalize
+// its casing, then fetches and saves its dependency window, outside of
+// the queue-driven Run loop.
+func (c *Crawler) Seed(ctx context.Context, ref string) error {
+	repo, err := c.src.Resolve(ctx, ref)
+	if err != nil {
+		return fmt.Errorf("failed to resolve seed %s: %w", ref, err)
+	}
+
+	return c.process(ctx, repo.FQN)
+}
+
+// Run feeds a worker pool from Store.NextBatch until the queue is empty or
+// ctx is cancelled. On cancellation, Run stops pulling new work and waits
+// for in-flight fetches to finish before returning.
+func (c *Crawler) Run(ctx context.Context) error {
+	refs := make(chan string)
+	var wg sync.WaitGroup
+
+	wg.Add(c.workers)
+	for i := 0; i < c.workers; i++ {
+		go func() {
+			defer wg.Done()
+			for ref := range refs {
+				if err := c.process(ctx, ref); err != nil {
+					log.Printf("crawler: failed to process %s: %s", ref, err)
+				}
+			}
+		}()
+	}
+
+	c.dispatch(ctx, refs)
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// dispatch pulls batches from the store and feeds them to refs until the
+// queue is empty or ctx is cancelled, then closes refs so the worker pool
+// can drain and exit.
+func (c *Crawler) dispatch(ctx context.Context, refs chan<- string) {
+	defer close(refs)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		batch, err := c.store.NextBatch(ctx, c.workers)
+		if err != nil {
+			log.Printf("crawler: failed to fetch next batch: %s", err)
+			return
+		}
+
+		if len(batch) == 0 {
+			return
+		}
+
+		for i, ref := range batch {
+			select {
+			case refs <- ref:
+			case <-ctx.Done():
+				c.releaseUndispatched(batch[i:])
+				return
+			}
+		}
+	}
+}
+
+// releaseUndispatched gives refs back to the store after dispatch was
+// cancelled before it could hand them to a worker. It uses a fresh context,
+// since ctx is already cancelled by the time this runs, and only logs on
+// failure: dispatch is already on its way out and has nothing useful to do
+// with the error besides leaving refs in-flight until they're noticed.
+func (c *Crawler) releaseUndispatched(refs []string) {
+	if len(refs) == 0 {
+		return
+	}
+
+	if err := c.store.Release(context.Background(), refs); err != nil {
+		log.Printf("crawler: failed to release %d undispatched ref(s): %s", len(refs), err)
+	}
+}
+
+func (c *Crawler) process(ctx context.Context, ref string) error {
+	var wg sync.WaitGroup
+	var dependencies, dependents []source.Repository
+	var errs struct {
+		dependencies error
+		dependents   error
+	}
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		dependents, errs.dependents = c.src.GetDependents(ctx, ref)
+	}()
+
+	go func() {
+		defer wg.Done()
+		dependencies, errs.dependencies = c.src.GetDependencies(ctx, ref)
+	}()
+
+	wg.Wait()
+	if errs.dependencies != nil {
+		return fmt.Errorf("failed to fetch dependencies: %w", errs.dependencies)
+	}
+
+	if errs.dependents != nil && !errors.Is(errs.dependents, source.ErrUnsupported) {
+		return fmt.Errorf("failed to fetch dependents: %w", errs.dependents)
+	}
+
+	return c.store.SaveWindow(ctx, ref, dependencies, dependents)
+}
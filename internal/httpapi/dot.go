@@ -0,0 +1,59 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/trinitroglycerin/dependagraph/internal/source"
+	"github.com/trinitroglycerin/dependagraph/internal/storage"
+)
+
+// wantsDOT reports whether the client asked for a Graphviz DOT graph
+// instead of the default JSON response.
+func wantsDOT(r *http.Request) bool {
+	return r.Header.Get("Accept") == "application/vnd.graphviz"
+}
+
+// edge is one relationship to render. Edges from edgesFromQuery point
+// from the dependent to the dependency; edges from edgesAlong follow the
+// path's order, whichever way DEPENDS_ON points along it.
+type edge struct {
+	from, to string
+}
+
+// edgesFromQuery renders a QueryResult's Edges, one hop per node along a
+// shortest path back to the query's ref, rather than a flat star from
+// ref to every node in the result: that's what lets the DOT output show
+// the actual dependency chain instead of a single level of fan-out.
+func edgesFromQuery(edges []storage.Edge) []edge {
+	out := make([]edge, len(edges))
+	for i, e := range edges {
+		out[i] = edge{from: e.From, to: e.To}
+	}
+
+	return out
+}
+
+// edgesAlong renders the consecutive hops of an ordered path.
+func edgesAlong(path []source.Repository) []edge {
+	if len(path) < 2 {
+		return nil
+	}
+
+	edges := make([]edge, len(path)-1)
+	for i := 0; i+1 < len(path); i++ {
+		edges[i] = edge{from: path[i].FQN, to: path[i+1].FQN}
+	}
+
+	return edges
+}
+
+// writeDOT streams edges as a Graphviz DOT digraph.
+func writeDOT(w http.ResponseWriter, edges []edge) {
+	w.Header().Set("Content-Type", "application/vnd.graphviz")
+	fmt.Fprintln(w, "digraph dependagraph {")
+	for _, e := range edges {
+		fmt.Fprintf(w, "\t%q -> %q;\n", e.from, e.to)
+	}
+	fmt.Fprintln(w, "}")
+}
@@ -0,0 +1,180 @@
+// Package httpapi exposes a read-only HTTP query API over a crawled
+// dependency graph: dependency and dependent walks, the shortest path
+// between two repositories, and a repository's transitive impact.
+// Responses are JSON by default, or a Graphviz DOT graph when the
+// request sends "Accept: application/vnd.graphviz".
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/trinitroglycerin/dependagraph/internal/source"
+	"github.com/trinitroglycerin/dependagraph/internal/storage"
+)
+
+// Server serves the query API over graph.
+type Server struct {
+	graph storage.Graph
+	token string
+}
+
+// NewServer returns a Server backed by graph. If token is non-empty,
+// every request must present it via "Authorization: Bearer <token>" or
+// "X-GitHub-Token: <token>"; otherwise the API is open.
+func NewServer(graph storage.Graph, token string) *Server {
+	return &Server{graph: graph, token: token}
+}
+
+// Handler returns the API's routes, wrapped in token authentication.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repo/", s.handleRepo)
+	mux.HandleFunc("/path", s.handlePath)
+	mux.HandleFunc("/impact/", s.handleImpact)
+
+	return s.authenticate(mux)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		got := r.Header.Get("X-GitHub-Token")
+		if got == "" {
+			got = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		}
+		if got != s.token {
+			http.Error(w, "missing or invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleRepo serves GET /repo/{org}/{repo}/dependencies and
+// GET /repo/{org}/{repo}/dependents.
+func (s *Server) handleRepo(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/repo/"), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		http.NotFound(w, r)
+		return
+	}
+	ref := parts[0] + "/" + parts[1]
+
+	var kind storage.QueryKind
+	switch parts[2] {
+	case "dependencies":
+		kind = storage.QueryDependencies
+	case "dependents":
+		kind = storage.QueryDependents
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	depth, err := parseDepth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.graph.Query(r.Context(), storage.Query{Kind: kind, Ref: ref, Depth: depth})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if wantsDOT(r) {
+		writeDOT(w, edgesFromQuery(result.Edges))
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{"repository": ref, "nodes": fqns(result.Nodes)})
+}
+
+// handlePath serves GET /path?from=a/b&to=c/d.
+func (s *Server) handlePath(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	if from == "" || to == "" {
+		http.Error(w, "from and to query parameters are required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.graph.Query(r.Context(), storage.Query{Kind: storage.QueryPath, From: from, To: to})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if wantsDOT(r) {
+		writeDOT(w, edgesAlong(result.Path))
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{"from": from, "to": to, "path": fqns(result.Path)})
+}
+
+// handleImpact serves GET /impact/{org}/{repo}.
+func (s *Server) handleImpact(w http.ResponseWriter, r *http.Request) {
+	ref := strings.TrimPrefix(r.URL.Path, "/impact/")
+	if parts := strings.Split(ref, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	result, err := s.graph.Query(r.Context(), storage.Query{Kind: storage.QueryImpact, Ref: ref})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if wantsDOT(r) {
+		writeDOT(w, edgesFromQuery(result.Edges))
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"repository":   ref,
+		"dependents":   fqns(result.Nodes),
+		"depth_counts": result.DepthCounts,
+	})
+}
+
+func parseDepth(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("depth")
+	if v == "" {
+		return 0, nil
+	}
+
+	depth, err := strconv.Atoi(v)
+	if err != nil || depth < 0 {
+		return 0, fmt.Errorf("invalid depth %q: must be a non-negative integer", v)
+	}
+
+	return depth, nil
+}
+
+func fqns(repos []source.Repository) []string {
+	out := make([]string, len(repos))
+	for i, r := range repos {
+		out[i] = r.FQN
+	}
+
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
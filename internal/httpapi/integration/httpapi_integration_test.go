@@ -0,0 +1,167 @@
+//go:build integration
+
+// Integration tests against a real Neo4j, run via testcontainers. They
+// need a Docker daemon and live in their own module (see go.mod in this
+// directory) so that testcontainers' dependency tree doesn't weigh down
+// the installable dependagraph module. Invoke them explicitly with
+// `go test -tags=integration ./...` from this directory.
+package integration_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tcneo4j "github.com/testcontainers/testcontainers-go/modules/neo4j"
+
+	"github.com/trinitroglycerin/dependagraph/internal/httpapi"
+	"github.com/trinitroglycerin/dependagraph/internal/source"
+	"github.com/trinitroglycerin/dependagraph/internal/storage"
+)
+
+func TestQueryAPIAgainstNeo4j(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := tcneo4j.RunContainer(ctx, tcneo4j.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("starting neo4j container: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Errorf("terminating neo4j container: %s", err)
+		}
+	})
+
+	boltURL, err := container.BoltUrl(ctx)
+	if err != nil {
+		t.Fatalf("getting bolt url: %s", err)
+	}
+
+	graph, err := storage.NewNeo4jGraph(boltURL, "", "")
+	if err != nil {
+		t.Fatalf("connecting to neo4j: %s", err)
+	}
+	t.Cleanup(func() { graph.Close() })
+
+	// a/a depends on b/b depends on c/c.
+	if err := graph.SaveWindow(ctx, "a/a", []source.Repository{{FQN: "b/b"}}, nil); err != nil {
+		t.Fatalf("seeding a/a: %s", err)
+	}
+	if err := graph.SaveWindow(ctx, "b/b", []source.Repository{{FQN: "c/c"}}, nil); err != nil {
+		t.Fatalf("seeding b/b: %s", err)
+	}
+
+	srv := httptest.NewServer(httpapi.NewServer(graph, "").Handler())
+	t.Cleanup(srv.Close)
+
+	t.Run("dependencies JSON", func(t *testing.T) {
+		var body struct {
+			Nodes []string `json:"nodes"`
+		}
+		getJSON(t, srv.URL+"/repo/a/a/dependencies", &body)
+
+		if !containsAll(body.Nodes, "b/b", "c/c") {
+			t.Errorf("dependencies of a/a = %v, want b/b and c/c", body.Nodes)
+		}
+	})
+
+	t.Run("dependencies DOT shows the chain, not a star", func(t *testing.T) {
+		dot := getDOT(t, srv.URL+"/repo/a/a/dependencies")
+
+		if !strings.Contains(dot, `"a/a" -> "b/b"`) || !strings.Contains(dot, `"b/b" -> "c/c"`) {
+			t.Errorf("dot output = %q, want edges a/a->b/b and b/b->c/c", dot)
+		}
+		if strings.Contains(dot, `"a/a" -> "c/c"`) {
+			t.Errorf("dot output = %q, should not draw a direct a/a->c/c edge", dot)
+		}
+	})
+
+	t.Run("path", func(t *testing.T) {
+		var body struct {
+			Path []string `json:"path"`
+		}
+		getJSON(t, srv.URL+"/path?from=a%2Fa&to=c%2Fc", &body)
+
+		want := []string{"a/a", "b/b", "c/c"}
+		if len(body.Path) != len(want) {
+			t.Fatalf("path = %v, want %v", body.Path, want)
+		}
+		for i := range want {
+			if body.Path[i] != want[i] {
+				t.Errorf("path = %v, want %v", body.Path, want)
+			}
+		}
+	})
+
+	t.Run("impact", func(t *testing.T) {
+		var body struct {
+			Dependents []string `json:"dependents"`
+		}
+		getJSON(t, srv.URL+"/impact/c/c", &body)
+
+		if !containsAll(body.Dependents, "a/a", "b/b") {
+			t.Errorf("impact of c/c = %v, want a/a and b/b", body.Dependents)
+		}
+	})
+}
+
+func getJSON(t *testing.T, url string, v interface{}) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %s", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: status %d", url, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decoding response from %s: %s", url, err)
+	}
+}
+
+func getDOT(t *testing.T, url string) string {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("building request for %s: %s", url, err)
+	}
+	req.Header.Set("Accept", "application/vnd.graphviz")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %s", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: status %d", url, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading response from %s: %s", url, err)
+	}
+
+	return string(body)
+}
+
+func containsAll(haystack []string, items ...string) bool {
+	set := make(map[string]bool, len(haystack))
+	for _, h := range haystack {
+		set[h] = true
+	}
+	for _, item := range items {
+		if !set[item] {
+			return false
+		}
+	}
+	return true
+}
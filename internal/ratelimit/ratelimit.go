@@ -0,0 +1,181 @@
+// Package ratelimit implements a limiter driven by the GitHub-style
+// X-RateLimit-Remaining/X-RateLimit-Reset response headers, with
+// exponential backoff and jitter for secondary (abuse) rate limits. It
+// also supports a configured per-hour token bucket, for sources (GitLab,
+// Gitea) whose responses don't carry rate-limit headers for Observe to
+// track.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// lowWatermark is how much of the primary rate limit budget we keep in
+// reserve before sleeping until the window resets.
+const lowWatermark = 50
+
+// Limiter tracks a remote API's rate limit state and blocks callers until
+// it is safe to issue another request. The zero value is not usable; use
+// New.
+type Limiter struct {
+	mu sync.Mutex
+
+	haveState bool
+	remaining int
+	resetAt   time.Time
+
+	secondaryUntil time.Time
+	backoffAttempt int
+
+	// bucket enforces a configured per-hour budget, independent of any
+	// header state Observe has seen. Nil means no configured budget.
+	bucket *tokenBucket
+}
+
+// New returns a Limiter with no observed state; the first Wait call
+// proceeds immediately. perHour, if positive, additionally enforces a
+// token-bucket budget of that many requests per hour; pass 0 to rely
+// solely on the headers Observe records.
+func New(perHour int) *Limiter {
+	l := &Limiter{}
+	if perHour > 0 {
+		l.bucket = newTokenBucket(perHour)
+	}
+
+	return l
+}
+
+// Observe updates the limiter from an HTTP response's rate limit headers.
+// A 403 response is treated as a secondary (abuse) rate limit: it honours
+// a Retry-After header if present, and otherwise backs off exponentially
+// with jitter.
+func (l *Limiter) Observe(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if resp.StatusCode == http.StatusForbidden {
+		wait := l.nextBackoffLocked()
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			wait = time.Duration(seconds) * time.Second
+		}
+		l.secondaryUntil = time.Now().Add(wait)
+		return
+	}
+	l.backoffAttempt = 0
+
+	if v := resp.Header.Get("X-RateLimit-Remaining"); v != "" {
+		if remaining, err := strconv.Atoi(v); err == nil {
+			l.remaining = remaining
+			l.haveState = true
+		}
+	}
+
+	if v := resp.Header.Get("X-RateLimit-Reset"); v != "" {
+		if epoch, err := strconv.ParseInt(v, 10, 64); err == nil {
+			l.resetAt = time.Unix(epoch, 0)
+		}
+	}
+}
+
+// nextBackoffLocked returns the next exponential-with-jitter backoff
+// duration: an exponential base capped at one minute, plus up to half that
+// base again as jitter. l.mu must be held.
+func (l *Limiter) nextBackoffLocked() time.Duration {
+	base := time.Second * time.Duration(math.Pow(2, float64(l.backoffAttempt)))
+	if base > time.Minute {
+		base = time.Minute
+	}
+	l.backoffAttempt++
+
+	jitter := time.Duration(rand.Int63n(int64(base)/2 + 1))
+	return base + jitter
+}
+
+// Wait blocks until it is safe to issue another request: past any
+// secondary rate limit backoff window, under the primary rate limit
+// budget or past its reset time, and — if a per-hour budget was
+// configured — a token is available in that bucket.
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		wait := l.waitDuration()
+		if wait <= 0 && l.bucket != nil {
+			wait = l.bucket.take()
+		}
+		if wait <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (l *Limiter) waitDuration() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.secondaryUntil) {
+		return l.secondaryUntil.Sub(now)
+	}
+
+	if l.haveState && l.remaining <= lowWatermark && now.Before(l.resetAt) {
+		return l.resetAt.Sub(now)
+	}
+
+	return 0
+}
+
+// tokenBucket is a classic token bucket refilled continuously at
+// perHour/3600 tokens per second, capped at perHour tokens.
+type tokenBucket struct {
+	mu sync.Mutex
+
+	capacity   float64
+	tokens     float64
+	refillRate float64 // tokens per second
+	last       time.Time
+}
+
+func newTokenBucket(perHour int) *tokenBucket {
+	capacity := float64(perHour)
+	return &tokenBucket{
+		capacity:   capacity,
+		tokens:     capacity,
+		refillRate: capacity / 3600,
+		last:       time.Now(),
+	}
+}
+
+// take consumes a token and returns 0 if one is available, or returns
+// how long to wait until one will be without consuming it.
+func (b *tokenBucket) take() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.refillRate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / b.refillRate * float64(time.Second))
+}
@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketTake(t *testing.T) {
+	b := newTokenBucket(3600) // 1 token/sec
+	b.last = time.Now().Add(-2 * time.Second)
+
+	if wait := b.take(); wait != 0 {
+		t.Fatalf("take() with tokens available = %v, want 0", wait)
+	}
+
+	b.tokens = 0
+	b.last = time.Now()
+	if wait := b.take(); wait <= 0 {
+		t.Fatalf("take() with no tokens available = %v, want > 0", wait)
+	}
+}
+
+func TestTokenBucketCapsAtCapacity(t *testing.T) {
+	b := newTokenBucket(10)
+	b.tokens = 10
+	b.last = time.Now().Add(-time.Hour)
+
+	if wait := b.take(); wait != 0 {
+		t.Fatalf("take() = %v, want 0", wait)
+	}
+	if b.tokens > b.capacity {
+		t.Fatalf("tokens = %v, want capped at capacity %v", b.tokens, b.capacity)
+	}
+}
+
+func TestNewWithoutPerHourHasNoBucket(t *testing.T) {
+	l := New(0)
+	if l.bucket != nil {
+		t.Fatalf("New(0) bucket = %v, want nil", l.bucket)
+	}
+}
+
+func TestNewWithPerHourConfiguresBucket(t *testing.T) {
+	l := New(60)
+	if l.bucket == nil {
+		t.Fatal("New(60) bucket = nil, want configured")
+	}
+}
+
+func TestWaitDurationNoState(t *testing.T) {
+	l := New(0)
+	if wait := l.waitDuration(); wait != 0 {
+		t.Fatalf("waitDuration() with no observed state = %v, want 0", wait)
+	}
+}
+
+func TestWaitDurationAboveWatermark(t *testing.T) {
+	l := New(0)
+	l.haveState = true
+	l.remaining = lowWatermark + 1
+	l.resetAt = time.Now().Add(time.Hour)
+
+	if wait := l.waitDuration(); wait != 0 {
+		t.Fatalf("waitDuration() with remaining above watermark = %v, want 0", wait)
+	}
+}
+
+func TestWaitDurationAtWatermarkWaitsForReset(t *testing.T) {
+	l := New(0)
+	l.haveState = true
+	l.remaining = lowWatermark
+	l.resetAt = time.Now().Add(time.Minute)
+
+	if wait := l.waitDuration(); wait <= 0 {
+		t.Fatalf("waitDuration() at watermark = %v, want > 0", wait)
+	}
+}
+
+func TestWaitDurationSecondaryBackoff(t *testing.T) {
+	l := New(0)
+	l.secondaryUntil = time.Now().Add(time.Minute)
+
+	if wait := l.waitDuration(); wait <= 0 {
+		t.Fatalf("waitDuration() during secondary backoff = %v, want > 0", wait)
+	}
+}
+
+func TestObserveTracksRemainingAndReset(t *testing.T) {
+	l := New(0)
+	resetAt := time.Now().Add(time.Hour).Unix()
+
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+	}
+	resp.Header.Set("X-RateLimit-Remaining", "5")
+	resp.Header.Set("X-RateLimit-Reset", strconv.FormatInt(resetAt, 10))
+
+	l.Observe(resp)
+
+	if !l.haveState || l.remaining != 5 {
+		t.Fatalf("remaining = %d, haveState = %v, want 5, true", l.remaining, l.haveState)
+	}
+}
+
+func TestObserveForbiddenSetsSecondaryBackoff(t *testing.T) {
+	l := New(0)
+	resp := &http.Response{
+		StatusCode: http.StatusForbidden,
+		Header:     http.Header{},
+	}
+
+	l.Observe(resp)
+
+	if !l.secondaryUntil.After(time.Now()) {
+		t.Fatal("Observe(403) didn't set a future secondaryUntil")
+	}
+	if l.backoffAttempt != 1 {
+		t.Fatalf("backoffAttempt = %d, want 1", l.backoffAttempt)
+	}
+}
+
+func TestObserveForbiddenHonoursRetryAfter(t *testing.T) {
+	l := New(0)
+	resp := &http.Response{
+		StatusCode: http.StatusForbidden,
+		Header:     http.Header{},
+	}
+	resp.Header.Set("Retry-After", "30")
+
+	l.Observe(resp)
+
+	wait := time.Until(l.secondaryUntil)
+	if wait < 29*time.Second || wait > 30*time.Second {
+		t.Fatalf("secondaryUntil wait = %v, want ~30s", wait)
+	}
+}
+
+func TestObserveNilResponse(t *testing.T) {
+	l := New(0)
+	l.Observe(nil) // must not panic
+}
@@ -0,0 +1,153 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const defaultGiteaAPIURL = "https://gitea.com"
+
+// errNotFound signals a 404 from the Gitea API, so callers can decide for
+// themselves whether that means "nothing here" or a hard failure.
+var errNotFound = errors.New("gitea: not found")
+
+// GiteaSource implements DependencySource against gitea.com or a
+// self-hosted Gitea instance, using its SBOM endpoint.
+type GiteaSource struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewGiteaSource builds a GiteaSource. apiURL is the instance's base URL
+// (e.g. "https://git.example.org"); an empty string selects gitea.com.
+func NewGiteaSource(apiURL, token string) *GiteaSource {
+	if apiURL == "" {
+		apiURL = defaultGiteaAPIURL
+	}
+
+	return &GiteaSource{
+		baseURL:    strings.TrimSuffix(apiURL, "/"),
+		token:      token,
+		httpClient: http.DefaultClient,
+	}
+}
+
+// giteaSBOM is a minimal CycloneDX SBOM document, as returned by Gitea's
+// /repos/:owner/:repo/sbom endpoint. Only the fields Dependagraph needs are
+// modelled.
+type giteaSBOM struct {
+	Components []struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		PURL    string `json:"purl"`
+	} `json:"components"`
+}
+
+// GetDependencies fetches the repository's SBOM and returns its components
+// as dependencies. Not every repository has an SBOM available; a not-found
+// response is returned as no dependencies and a nil error rather than as a
+// failure.
+func (g *GiteaSource) GetDependencies(ctx context.Context, ref string) ([]Repository, error) {
+	org, name, err := splitRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var sbom giteaSBOM
+	path := fmt.Sprintf("/api/v1/repos/%s/%s/sbom", org, name)
+	if err := g.get(ctx, path, &sbom); err != nil {
+		if errors.Is(err, errNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gitea: fetching sbom for %s: %w", ref, err)
+	}
+
+	repos := make([]Repository, 0, len(sbom.Components))
+	for _, c := range sbom.Components {
+		repos = append(repos, Repository{
+			FQN:            c.Name,
+			Version:        c.Version,
+			PackageManager: purlType(c.PURL),
+		})
+	}
+
+	return repos, nil
+}
+
+// GetDependents is unsupported: Gitea exposes no reverse dependency
+// lookup, SBOM or otherwise.
+func (g *GiteaSource) GetDependents(ctx context.Context, ref string) ([]Repository, error) {
+	return nil, ErrUnsupported
+}
+
+// Resolve fetches the repository's metadata via /api/v1/repos/:owner/:repo.
+func (g *GiteaSource) Resolve(ctx context.Context, ref string) (Repository, error) {
+	org, name, err := splitRef(ref)
+	if err != nil {
+		return Repository{}, err
+	}
+
+	var repo struct {
+		FullName string `json:"full_name"`
+		HTMLURL  string `json:"html_url"`
+		Language string `json:"language"`
+	}
+
+	path := fmt.Sprintf("/api/v1/repos/%s/%s", org, name)
+	if err := g.get(ctx, path, &repo); err != nil {
+		return Repository{}, fmt.Errorf("gitea: resolving %s: %w", ref, err)
+	}
+
+	return Repository{
+		FQN:          repo.FullName,
+		Organization: org,
+		Repository:   name,
+		URL:          repo.HTMLURL,
+		Language:     repo.Language,
+	}, nil
+}
+
+// purlType extracts the package type from a Package URL, e.g. "npm" from
+// "pkg:npm/lodash@4.17.21". It returns "" if purl isn't a recognizable
+// pkg: URL.
+func purlType(purl string) string {
+	const prefix = "pkg:"
+	if !strings.HasPrefix(purl, prefix) {
+		return ""
+	}
+
+	rest := strings.TrimPrefix(purl, prefix)
+	if idx := strings.IndexByte(rest, '/'); idx >= 0 {
+		return rest[:idx]
+	}
+
+	return ""
+}
+
+func (g *GiteaSource) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", g.token))
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,309 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/machinebox/graphql"
+	"github.com/trinitroglycerin/dependagraph/internal/ratelimit"
+)
+
+const defaultGithubGraphQLURL = "https://api.github.com/graphql"
+
+// GitHubSource implements DependencySource against github.com or a GitHub
+// Enterprise instance.
+type GitHubSource struct {
+	client     *graphql.Client
+	token      string
+	httpClient *http.Client
+	// webBaseURL is the base URL of the HTML site (github.com or an
+	// Enterprise hostname), used to scrape the dependents page since
+	// there is no GraphQL API for it.
+	webBaseURL string
+}
+
+// NewGitHubSource builds a GitHubSource. apiURL is the GraphQL endpoint to
+// use (e.g. "https://github.example.org/api/graphql" for GitHub
+// Enterprise); an empty string selects github.com. If limiter is non-nil,
+// every request (GraphQL and HTML) is throttled against GitHub's
+// X-RateLimit-Remaining/X-RateLimit-Reset headers and backs off on
+// secondary rate limit responses.
+func NewGitHubSource(apiURL, token string, limiter *ratelimit.Limiter) *GitHubSource {
+	graphqlURL := apiURL
+	webBaseURL := "https://github.com"
+	if graphqlURL == "" {
+		graphqlURL = defaultGithubGraphQLURL
+	} else {
+		webBaseURL = strings.TrimSuffix(strings.Replace(graphqlURL, "/api/graphql", "", 1), "/graphql")
+	}
+
+	httpClient := &http.Client{
+		Transport: &rateLimitedTransport{base: http.DefaultTransport, limiter: limiter},
+	}
+
+	return &GitHubSource{
+		client:     graphql.NewClient(graphqlURL, graphql.WithHTTPClient(httpClient)),
+		token:      token,
+		httpClient: httpClient,
+		webBaseURL: webBaseURL,
+	}
+}
+
+// rateLimitedTransport waits on limiter before issuing each request and
+// feeds the response back to it, so the limiter's view of the remote's
+// rate limit budget stays current without every call site having to know
+// about it.
+type rateLimitedTransport struct {
+	base    http.RoundTripper
+	limiter *ratelimit.Limiter
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.limiter != nil {
+		if err := t.limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if t.limiter != nil {
+		t.limiter.Observe(resp)
+	}
+
+	return resp, err
+}
+
+type dependencyGraphResponse struct {
+	Repository struct {
+		DependencyGraphManifests struct {
+			Edges []struct {
+				Node struct {
+					BlobPath     string `json:"blobPath"`
+					Dependencies struct {
+						Nodes []struct {
+							PackageName    string `json:"packageName"`
+							PackageManager string `json:"packageManager"`
+							Requirements   string `json:"requirements"`
+							Repository     *struct {
+								NameWithOwner   string `json:"nameWithOwner"`
+								URL             string `json:"url"`
+								PrimaryLanguage *struct {
+									Name string `json:"name"`
+								} `json:"primaryLanguage"`
+							} `json:"repository"`
+						} `json:"nodes"`
+					} `json:"dependencies"`
+				} `json:"node"`
+			} `json:"edges"`
+		} `json:"dependencyGraphManifests"`
+	} `json:"repository"`
+}
+
+// GetDependencies queries GitHub's GraphQL endpoint to return the set of
+// all dependencies that this repository depends upon.
+func (g *GitHubSource) GetDependencies(ctx context.Context, ref string) ([]Repository, error) {
+	org, name, err := splitRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	req := graphql.NewRequest(`
+	query GetDependencies($org: String!, $name: String!) {
+			repository(owner: $org, name: $name) {
+					dependencyGraphManifests {
+							edges {
+									node {
+									blobPath
+									dependencies {
+													nodes {
+															packageName
+															packageManager
+															requirements
+															repository {
+																nameWithOwner
+																url
+																primaryLanguage {
+																	name
+																}
+															}
+													}
+											}
+									}
+							}
+					}
+			}
+	}`)
+	req.Var("org", org)
+	req.Var("name", name)
+	req.Header.Set("Accept", "application/vnd.github.hawkgirl-preview+json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", g.token))
+
+	var resp dependencyGraphResponse
+	if err := g.client.Run(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("github: fetching dependency graph for %s: %w", ref, err)
+	}
+
+	var deps []Repository
+	for _, edge := range resp.Repository.DependencyGraphManifests.Edges {
+		for _, node := range edge.Node.Dependencies.Nodes {
+			dep := Repository{
+				FQN:            node.PackageName,
+				Version:        node.Requirements,
+				PackageManager: node.PackageManager,
+			}
+
+			if node.Repository != nil {
+				dep.FQN = node.Repository.NameWithOwner
+				dep.URL = node.Repository.URL
+				if node.Repository.PrimaryLanguage != nil {
+					dep.Language = node.Repository.PrimaryLanguage.Name
+				}
+				if o, r, err := splitRef(node.Repository.NameWithOwner); err == nil {
+					dep.Organization, dep.Repository = o, r
+				}
+			}
+
+			deps = append(deps, dep)
+		}
+	}
+
+	return deps, nil
+}
+
+// dependentsLinkPattern matches the repository links GitHub renders on the
+// network/dependents page, e.g. <a data-hovercard-type="repository"
+// href="/some-org/some-repo">.
+var dependentsLinkPattern = regexp.MustCompile(`data-hovercard-type="repository"[^>]*href="/([^"/]+/[^"/]+)"`)
+
+// dependentsNextPagePattern extracts the "Next" pagination link's cursor
+// from the dependents page.
+var dependentsNextPagePattern = regexp.MustCompile(`<a[^>]*href="([^"]*dependents[^"]*)"[^>]*>\s*Next\s*</a>`)
+
+// GetDependents scrapes the "Used by" / network/dependents page, since
+// GitHub's GraphQL API has no field for reverse dependencies.
+func (g *GitHubSource) GetDependents(ctx context.Context, ref string) ([]Repository, error) {
+	nextURL := fmt.Sprintf("%s/%s/network/dependents", g.webBaseURL, ref)
+
+	var dependents []Repository
+	seen := map[string]bool{}
+
+	for nextURL != "" {
+		body, err := g.fetchHTML(ctx, nextURL)
+		if err != nil {
+			return nil, fmt.Errorf("github: fetching dependents for %s: %w", ref, err)
+		}
+
+		for _, match := range dependentsLinkPattern.FindAllStringSubmatch(body, -1) {
+			fqn := match[1]
+			if seen[fqn] {
+				continue
+			}
+			seen[fqn] = true
+
+			org, name, err := splitRef(fqn)
+			if err != nil {
+				continue
+			}
+
+			dependents = append(dependents, Repository{
+				FQN:          fqn,
+				Organization: org,
+				Repository:   name,
+				URL:          fmt.Sprintf("%s/%s", g.webBaseURL, fqn),
+			})
+		}
+
+		nextURL = ""
+		if match := dependentsNextPagePattern.FindStringSubmatch(body); match != nil {
+			nextURL = g.webBaseURL + match[1]
+		}
+	}
+
+	return dependents, nil
+}
+
+// Resolve looks up ref via GitHub's GraphQL API and returns its canonical
+// Repository representation.
+func (g *GitHubSource) Resolve(ctx context.Context, ref string) (Repository, error) {
+	org, name, err := splitRef(ref)
+	if err != nil {
+		return Repository{}, err
+	}
+
+	req := graphql.NewRequest(`
+	query Resolve($org: String!, $name: String!) {
+			repository(owner: $org, name: $name) {
+					nameWithOwner
+					url
+					primaryLanguage {
+						name
+					}
+			}
+	}`)
+	req.Var("org", org)
+	req.Var("name", name)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", g.token))
+
+	var resp struct {
+		Repository struct {
+			NameWithOwner   string `json:"nameWithOwner"`
+			URL             string `json:"url"`
+			PrimaryLanguage *struct {
+				Name string `json:"name"`
+			} `json:"primaryLanguage"`
+		} `json:"repository"`
+	}
+
+	if err := g.client.Run(ctx, req, &resp); err != nil {
+		return Repository{}, fmt.Errorf("github: resolving %s: %w", ref, err)
+	}
+
+	repo := Repository{
+		FQN:          resp.Repository.NameWithOwner,
+		Organization: org,
+		Repository:   name,
+		URL:          resp.Repository.URL,
+	}
+	if resp.Repository.PrimaryLanguage != nil {
+		repo.Language = resp.Repository.PrimaryLanguage.Name
+	}
+
+	return repo, nil
+}
+
+func (g *GitHubSource) fetchHTML(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
+
+func splitRef(ref string) (org, name string, err error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("source: %q must have exactly one slash", ref)
+	}
+
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,127 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultGitlabAPIURL = "https://gitlab.com"
+
+// GitLabSource implements DependencySource against gitlab.com or a
+// self-managed GitLab instance, using the Dependency List API.
+type GitLabSource struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewGitLabSource builds a GitLabSource. apiURL is the instance's base URL
+// (e.g. "https://gitlab.example.org"); an empty string selects gitlab.com.
+func NewGitLabSource(apiURL, token string) *GitLabSource {
+	if apiURL == "" {
+		apiURL = defaultGitlabAPIURL
+	}
+
+	return &GitLabSource{
+		baseURL:    strings.TrimSuffix(apiURL, "/"),
+		token:      token,
+		httpClient: http.DefaultClient,
+	}
+}
+
+type gitlabProject struct {
+	ID                int    `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	WebURL            string `json:"web_url"`
+}
+
+type gitlabDependency struct {
+	Name           string `json:"name"`
+	Version        string `json:"version"`
+	PackageManager string `json:"package_manager"`
+}
+
+// GetDependencies fetches the project's dependency list via
+// /api/v4/projects/:id/dependencies.
+func (g *GitLabSource) GetDependencies(ctx context.Context, ref string) ([]Repository, error) {
+	project, err := g.project(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("gitlab: resolving project %s: %w", ref, err)
+	}
+
+	var deps []gitlabDependency
+	if err := g.get(ctx, fmt.Sprintf("/api/v4/projects/%d/dependencies", project.ID), &deps); err != nil {
+		return nil, fmt.Errorf("gitlab: fetching dependencies for %s: %w", ref, err)
+	}
+
+	repos := make([]Repository, 0, len(deps))
+	for _, dep := range deps {
+		repos = append(repos, Repository{
+			FQN:            dep.Name,
+			Version:        dep.Version,
+			PackageManager: dep.PackageManager,
+		})
+	}
+
+	return repos, nil
+}
+
+// GetDependents is unsupported: GitLab has no public API for reverse
+// dependency lookups.
+func (g *GitLabSource) GetDependents(ctx context.Context, ref string) ([]Repository, error) {
+	return nil, ErrUnsupported
+}
+
+// Resolve fetches the project's metadata via /api/v4/projects/:id.
+func (g *GitLabSource) Resolve(ctx context.Context, ref string) (Repository, error) {
+	project, err := g.project(ctx, ref)
+	if err != nil {
+		return Repository{}, fmt.Errorf("gitlab: resolving %s: %w", ref, err)
+	}
+
+	org, name, err := splitRef(project.PathWithNamespace)
+	if err != nil {
+		return Repository{}, err
+	}
+
+	return Repository{
+		FQN:          project.PathWithNamespace,
+		Organization: org,
+		Repository:   name,
+		URL:          project.WebURL,
+	}, nil
+}
+
+func (g *GitLabSource) project(ctx context.Context, ref string) (gitlabProject, error) {
+	var project gitlabProject
+	path := fmt.Sprintf("/api/v4/projects/%s", url.PathEscape(ref))
+	if err := g.get(ctx, path, &project); err != nil {
+		return gitlabProject{}, err
+	}
+
+	return project, nil
+}
+
+func (g *GitLabSource) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", g.token)
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,84 @@
+// Package source abstracts over the different code-hosting forges that
+// Dependagraph can crawl for dependency information. GitHub, GitLab and
+// Gitea each expose dependency data through a different API shape; this
+// package hides that behind a single DependencySource interface so the
+// crawler and storage layers never need to know which forge they're
+// talking to.
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/trinitroglycerin/dependagraph/internal/ratelimit"
+)
+
+// ErrUnsupported is returned by a DependencySource method when the
+// underlying forge has no API to answer the request (for example, GitLab
+// and Gitea have no equivalent of GitHub's dependents page).
+var ErrUnsupported = errors.New("source: not supported by this forge")
+
+// Repository is a single node discovered while crawling a forge: either the
+// repository being scraped itself, or one of its dependencies/dependents.
+type Repository struct {
+	FQN, Organization, Repository, URL, Version, Language string
+	// PackageManager is the ecosystem the forge reported this dependency
+	// as belonging to (e.g. "NPM", "GO", "PIP"), when known. It's only
+	// populated for entries returned from GetDependencies.
+	PackageManager string
+}
+
+// DependencySource is implemented once per code-hosting forge (GitHub,
+// GitLab, Gitea, ...). ref is always the forge-native "org/repo" path.
+type DependencySource interface {
+	// GetDependencies returns the repositories that ref depends upon.
+	GetDependencies(ctx context.Context, ref string) ([]Repository, error)
+	// GetDependents returns the repositories that depend upon ref.
+	GetDependents(ctx context.Context, ref string) ([]Repository, error)
+	// Resolve looks up ref on the forge and returns the canonical
+	// Repository it refers to.
+	Resolve(ctx context.Context, ref string) (Repository, error)
+}
+
+// Kind identifies a supported forge type, used to select a
+// DependencySource implementation from configuration or CLI flags.
+type Kind string
+
+const (
+	KindGithub Kind = "github"
+	KindGitlab Kind = "gitlab"
+	KindGitea  Kind = "gitea"
+)
+
+// Config carries the connection details needed to construct a
+// DependencySource: where the forge's API lives, and how to authenticate
+// against it.
+type Config struct {
+	// APIURL is the base URL of the forge's API. Empty selects the
+	// public SaaS instance (api.github.com, gitlab.com, ...).
+	APIURL string
+	Token  string
+	// Limiter, if set, throttles every request the source issues to stay
+	// under the forge's rate limit.
+	Limiter *ratelimit.Limiter
+}
+
+// New constructs the DependencySource for the given forge Kind.
+func New(kind Kind, cfg Config) (DependencySource, error) {
+	switch kind {
+	case KindGithub, "":
+		return NewGitHubSource(cfg.APIURL, cfg.Token, cfg.Limiter), nil
+	case KindGitlab:
+		s := NewGitLabSource(cfg.APIURL, cfg.Token)
+		s.httpClient = &http.Client{Transport: &rateLimitedTransport{base: http.DefaultTransport, limiter: cfg.Limiter}}
+		return s, nil
+	case KindGitea:
+		s := NewGiteaSource(cfg.APIURL, cfg.Token)
+		s.httpClient = &http.Client{Transport: &rateLimitedTransport{base: http.DefaultTransport, limiter: cfg.Limiter}}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("source: unknown forge kind %q", kind)
+	}
+}
@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/trinitroglycerin/dependagraph/internal/source"
+)
+
+// MemoryGraph is an in-process, non-persistent Graph backed by an
+// adjacency map. It's useful for trying Dependagraph out, or for tests,
+// without an external dependency.
+type MemoryGraph struct {
+	mu       sync.Mutex
+	targeted map[string]bool
+	inFlight map[string]bool
+	// edges is an undirected adjacency, used to find the crawl frontier
+	// and shortest paths, where direction doesn't matter.
+	edges map[string]map[string]bool
+	// forward and backward are the directed DEPENDS_ON adjacency, used to
+	// answer dependency/dependent/impact queries.
+	forward, backward map[string]map[string]bool
+	// dependencies holds requirement/package-manager metadata for the
+	// dependencies direction of edges, keyed by from -> to.
+	dependencies map[string]map[string]DependencyEdge
+	versions     map[string]versionInfo
+}
+
+type versionInfo struct {
+	latest   string
+	outdated bool
+}
+
+// NewMemoryGraph returns an empty MemoryGraph.
+func NewMemoryGraph() *MemoryGraph {
+	return &MemoryGraph{
+		targeted:     map[string]bool{},
+		inFlight:     map[string]bool{},
+		edges:        map[string]map[string]bool{},
+		forward:      map[string]map[string]bool{},
+		backward:     map[string]map[string]bool{},
+		dependencies: map[string]map[string]DependencyEdge{},
+		versions:     map[string]versionInfo{},
+	}
+}
+
+func (m *MemoryGraph) SaveWindow(ctx context.Context, ref string, dependencies, dependents []source.Repository) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.targeted[ref] = true
+	delete(m.inFlight, ref)
+
+	for _, dep := range dependencies {
+		m.linkLocked(ref, dep.FQN)
+
+		if m.dependencies[ref] == nil {
+			m.dependencies[ref] = map[string]DependencyEdge{}
+		}
+		m.dependencies[ref][dep.FQN] = DependencyEdge{
+			From: ref, To: dep.FQN,
+			Requirement:    dep.Version,
+			PackageManager: dep.PackageManager,
+			IsRepository:   dep.Organization != "" && dep.Repository != "",
+		}
+	}
+	for _, dep := range dependents {
+		m.linkLocked(dep.FQN, ref)
+	}
+
+	return nil
+}
+
+// linkLocked records that from depends on to: an undirected edge for
+// crawl-frontier and path purposes, plus a directed one for
+// dependency/dependent/impact queries.
+func (m *MemoryGraph) linkLocked(from, to string) {
+	if m.edges[from] == nil {
+		m.edges[from] = map[string]bool{}
+	}
+	m.edges[from][to] = true
+
+	if m.edges[to] == nil {
+		m.edges[to] = map[string]bool{}
+	}
+	m.edges[to][from] = true
+
+	if m.forward[from] == nil {
+		m.forward[from] = map[string]bool{}
+	}
+	m.forward[from][to] = true
+
+	if m.backward[to] == nil {
+		m.backward[to] = map[string]bool{}
+	}
+	m.backward[to][from] = true
+}
+
+func (m *MemoryGraph) NextBatch(ctx context.Context, n int) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var refs []string
+	for ref := range m.edges {
+		if len(refs) >= n {
+			break
+		}
+		if m.targeted[ref] || m.inFlight[ref] || strings.Contains(ref, ".") {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+
+	for _, ref := range refs {
+		m.inFlight[ref] = true
+	}
+
+	return refs, nil
+}
+
+// Release clears the in-flight flag on refs without marking them
+// targeted, so a future NextBatch call can hand them out again.
+func (m *MemoryGraph) Release(ctx context.Context, refs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, ref := range refs {
+		delete(m.inFlight, ref)
+	}
+
+	return nil
+}
+
+func (m *MemoryGraph) Neighbors(ctx context.Context, ref string) ([]source.Repository, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var repos []source.Repository
+	for n := range m.edges[ref] {
+		repos = append(repos, source.Repository{FQN: n})
+	}
+
+	return repos, nil
+}
+
+// DependencyEdges returns every edge that carries a declared requirement.
+func (m *MemoryGraph) DependencyEdges(ctx context.Context) ([]DependencyEdge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var edges []DependencyEdge
+	for _, byTo := range m.dependencies {
+		for _, edge := range byTo {
+			if edge.Requirement == "" {
+				continue
+			}
+			edges = append(edges, edge)
+		}
+	}
+
+	return edges, nil
+}
+
+// SetDependencyVersion records the latest known release for the
+// dependency node identified by fqn, and whether it's outdated.
+func (m *MemoryGraph) SetDependencyVersion(ctx context.Context, fqn, latestVersion string, outdated bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.versions[fqn] = versionInfo{latest: latestVersion, outdated: outdated}
+	return nil
+}
+
+// Query answers dependency/dependent/path/impact traversals over the
+// in-memory adjacency.
+func (m *MemoryGraph) Query(ctx context.Context, q Query) (QueryResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch q.Kind {
+	case QueryDependencies:
+		nodes, _, parent := m.bfsWithDepthLimitLocked(m.forward, q.Ref, q.Depth)
+		return QueryResult{Nodes: nodes, Edges: edgesFromParent(parent, true)}, nil
+	case QueryDependents:
+		nodes, _, parent := m.bfsWithDepthLimitLocked(m.backward, q.Ref, q.Depth)
+		return QueryResult{Nodes: nodes, Edges: edgesFromParent(parent, false)}, nil
+	case QueryImpact:
+		nodes, counts, parent := m.bfsWithDepthLimitLocked(m.backward, q.Ref, 0)
+		return QueryResult{Nodes: nodes, DepthCounts: counts, Edges: edgesFromParent(parent, false)}, nil
+	case QueryPath:
+		return QueryResult{Path: m.shortestPathLocked(q.From, q.To)}, nil
+	default:
+		return QueryResult{}, fmt.Errorf("storage: unknown query kind %v", q.Kind)
+	}
+}
+
+// bfsWithDepthLimitLocked walks adj from start up to maxDepth hops (0
+// means unlimited) and returns every node reached, how many were found
+// at each hop count, and a parent map (child -> the node it was reached
+// from) that callers use to reconstruct the shortest-path edges back to
+// start.
+func (m *MemoryGraph) bfsWithDepthLimitLocked(adj map[string]map[string]bool, start string, maxDepth int) ([]source.Repository, map[int]int, map[string]string) {
+	visited := map[string]bool{start: true}
+	counts := map[int]int{}
+	parent := map[string]string{}
+	var nodes []source.Repository
+
+	frontier := []string{start}
+	for depth := 1; len(frontier) > 0 && (maxDepth == 0 || depth <= maxDepth); depth++ {
+		var next []string
+		for _, ref := range frontier {
+			for n := range adj[ref] {
+				if visited[n] {
+					continue
+				}
+				visited[n] = true
+				parent[n] = ref
+				nodes = append(nodes, source.Repository{FQN: n})
+				counts[depth]++
+				next = append(next, n)
+			}
+		}
+		frontier = next
+	}
+
+	return nodes, counts, parent
+}
+
+// shortestPathLocked returns the shortest undirected path from from to
+// to, inclusive of both endpoints, or nil if they aren't connected.
+func (m *MemoryGraph) shortestPathLocked(from, to string) []source.Repository {
+	if from == to {
+		return []source.Repository{{FQN: from}}
+	}
+
+	visited := map[string]bool{from: true}
+	parent := map[string]string{}
+	frontier := []string{from}
+
+	for len(frontier) > 0 {
+		var next []string
+		for _, ref := range frontier {
+			for n := range m.edges[ref] {
+				if visited[n] {
+					continue
+				}
+				visited[n] = true
+				parent[n] = ref
+				if n == to {
+					return buildPath(parent, from, to)
+				}
+				next = append(next, n)
+			}
+		}
+		frontier = next
+	}
+
+	return nil
+}
+
+func buildPath(parent map[string]string, from, to string) []source.Repository {
+	var reversed []string
+	for at := to; at != from; at = parent[at] {
+		reversed = append(reversed, at)
+	}
+	reversed = append(reversed, from)
+
+	path := make([]source.Repository, len(reversed))
+	for i, fqn := range reversed {
+		path[len(reversed)-1-i] = source.Repository{FQN: fqn}
+	}
+
+	return path
+}
+
+func (m *MemoryGraph) Close() error {
+	return nil
+}
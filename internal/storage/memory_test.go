@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/trinitroglycerin/dependagraph/internal/source"
+)
+
+func fqns(repos []source.Repository) []string {
+	names := make([]string, len(repos))
+	for i, r := range repos {
+		names[i] = r.FQN
+	}
+	sort.Strings(names)
+	return names
+}
+
+// seedChain builds a -> b -> c (a depends on b, b depends on c) via
+// SaveWindow, the same entry point the crawler uses.
+func seedChain(t *testing.T, m *MemoryGraph) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := m.SaveWindow(ctx, "a", []source.Repository{{FQN: "b"}}, nil); err != nil {
+		t.Fatalf("SaveWindow(a): %v", err)
+	}
+	if err := m.SaveWindow(ctx, "b", []source.Repository{{FQN: "c"}}, []source.Repository{{FQN: "a"}}); err != nil {
+		t.Fatalf("SaveWindow(b): %v", err)
+	}
+}
+
+func TestMemoryGraphQueryDependencies(t *testing.T) {
+	m := NewMemoryGraph()
+	seedChain(t, m)
+
+	result, err := m.Query(context.Background(), Query{Kind: QueryDependencies, Ref: "a"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	if got := fqns(result.Nodes); len(got) != 2 || got[0] != "b" || got[1] != "c" {
+		t.Errorf("Nodes = %v, want [b c]", got)
+	}
+
+	if len(result.Edges) != 2 {
+		t.Fatalf("Edges = %v, want 2 edges", result.Edges)
+	}
+	want := map[Edge]bool{{From: "a", To: "b"}: true, {From: "b", To: "c"}: true}
+	for _, e := range result.Edges {
+		if !want[e] {
+			t.Errorf("unexpected edge %+v", e)
+		}
+	}
+}
+
+func TestMemoryGraphQueryDependenciesRespectsDepth(t *testing.T) {
+	m := NewMemoryGraph()
+	seedChain(t, m)
+
+	result, err := m.Query(context.Background(), Query{Kind: QueryDependencies, Ref: "a", Depth: 1})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	if got := fqns(result.Nodes); len(got) != 1 || got[0] != "b" {
+		t.Errorf("Nodes at depth 1 = %v, want [b]", got)
+	}
+}
+
+func TestMemoryGraphQueryDependents(t *testing.T) {
+	m := NewMemoryGraph()
+	seedChain(t, m)
+
+	result, err := m.Query(context.Background(), Query{Kind: QueryDependents, Ref: "c"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	if got := fqns(result.Nodes); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("Nodes = %v, want [a b]", got)
+	}
+
+	want := map[Edge]bool{{From: "b", To: "c"}: true, {From: "a", To: "b"}: true}
+	for _, e := range result.Edges {
+		if !want[e] {
+			t.Errorf("unexpected edge %+v", e)
+		}
+	}
+}
+
+func TestMemoryGraphQueryImpact(t *testing.T) {
+	m := NewMemoryGraph()
+	seedChain(t, m)
+
+	result, err := m.Query(context.Background(), Query{Kind: QueryImpact, Ref: "c"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	if got := fqns(result.Nodes); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("Nodes = %v, want [a b]", got)
+	}
+	if result.DepthCounts[1] != 1 || result.DepthCounts[2] != 1 {
+		t.Errorf("DepthCounts = %v, want 1 at depth 1 and depth 2", result.DepthCounts)
+	}
+}
+
+func TestMemoryGraphQueryPath(t *testing.T) {
+	m := NewMemoryGraph()
+	seedChain(t, m)
+
+	result, err := m.Query(context.Background(), Query{Kind: QueryPath, From: "a", To: "c"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	if got := fqns(result.Path); len(got) != 3 {
+		t.Fatalf("Path = %v, want 3 nodes", result.Path)
+	}
+	if result.Path[0].FQN != "a" || result.Path[len(result.Path)-1].FQN != "c" {
+		t.Errorf("Path = %v, want to start at a and end at c", result.Path)
+	}
+}
+
+func TestMemoryGraphQueryPathNoConnection(t *testing.T) {
+	m := NewMemoryGraph()
+	seedChain(t, m)
+	if err := m.SaveWindow(context.Background(), "unrelated", nil, nil); err != nil {
+		t.Fatalf("SaveWindow: %v", err)
+	}
+
+	result, err := m.Query(context.Background(), Query{Kind: QueryPath, From: "a", To: "unrelated"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if result.Path != nil {
+		t.Errorf("Path = %v, want nil for disconnected refs", result.Path)
+	}
+}
+
+func TestMemoryGraphNextBatchAndRelease(t *testing.T) {
+	m := NewMemoryGraph()
+	ctx := context.Background()
+	if err := m.SaveWindow(ctx, "a", []source.Repository{{FQN: "b"}}, nil); err != nil {
+		t.Fatalf("SaveWindow: %v", err)
+	}
+
+	batch, err := m.NextBatch(ctx, 10)
+	if err != nil {
+		t.Fatalf("NextBatch: %v", err)
+	}
+	sort.Strings(batch)
+	if len(batch) != 1 || batch[0] != "b" {
+		t.Fatalf("NextBatch = %v, want [b]", batch)
+	}
+
+	again, err := m.NextBatch(ctx, 10)
+	if err != nil {
+		t.Fatalf("NextBatch: %v", err)
+	}
+	if len(again) != 0 {
+		t.Fatalf("NextBatch while b is in-flight = %v, want none", again)
+	}
+
+	if err := m.Release(ctx, batch); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	released, err := m.NextBatch(ctx, 10)
+	if err != nil {
+		t.Fatalf("NextBatch: %v", err)
+	}
+	if len(released) != 1 || released[0] != "b" {
+		t.Fatalf("NextBatch after Release = %v, want [b] again", released)
+	}
+}
@@ -0,0 +1,436 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+	"github.com/trinitroglycerin/dependagraph/internal/source"
+)
+
+// Neo4jGraph stores the dependency graph in Neo4j as (:Repository) nodes
+// connected by DEPENDS_ON relationships.
+type Neo4jGraph struct {
+	driver neo4j.Driver
+}
+
+// NewNeo4jGraph opens a driver against uri, authenticating with
+// user/password. Sessions are opened per call rather than cached on the
+// struct, since neo4j.Session is not safe for concurrent use and
+// Neo4jGraph is shared across crawler workers.
+func NewNeo4jGraph(uri, user, password string) (*Neo4jGraph, error) {
+	driver, err := neo4j.NewDriver(uri, neo4j.BasicAuth(user, password, ""))
+	if err != nil {
+		return nil, fmt.Errorf("neo4j: opening driver for %s: %w", uri, err)
+	}
+
+	return &Neo4jGraph{driver: driver}, nil
+}
+
+func (n *Neo4jGraph) writeSession() neo4j.Session {
+	return n.driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+}
+
+func (n *Neo4jGraph) readSession() neo4j.Session {
+	return n.driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+}
+
+func (n *Neo4jGraph) SaveWindow(ctx context.Context, ref string, dependencies []source.Repository, dependents []source.Repository) error {
+	session := n.writeSession()
+	defer session.Close()
+
+	_, err := session.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		// Update the reference itself
+		_, err := tx.Run("MERGE (c:Repository {full_name: $full_name}) SET c.last_targeted = timestamp() REMOVE c.in_flight", map[string]interface{}{
+			"full_name": ref,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dep := range dependencies {
+			v := map[string]interface{}{
+				"full_name":       dep.FQN,
+				"ref":             ref,
+				"requirement":     dep.Version,
+				"package_manager": dep.PackageManager,
+				"is_repository":   dep.Organization != "" && dep.Repository != "",
+			}
+
+			if _, err := tx.Run(`MATCH (c:Repository {full_name: $ref}) WITH c
+				MERGE (c)-[rel:DEPENDS_ON]->(r:Repository {full_name: $full_name})
+				SET rel.requirement = $requirement, rel.package_manager = $package_manager, rel.is_repository = $is_repository`, v); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, dep := range dependents {
+			v := map[string]interface{}{
+				"full_name": dep.FQN,
+				"ref":       ref,
+			}
+
+			if _, err := tx.Run("MATCH (c:Repository {full_name: $ref}) WITH c MERGE (c)<-[:DEPENDS_ON]-(r:Repository {full_name: $full_name})", v); err != nil {
+				return nil, err
+			}
+		}
+
+		return nil, nil
+	})
+
+	return err
+}
+
+// NextBatch returns up to limit untargeted repositories and marks them
+// in-flight in the same transaction, so that a concurrent worker won't be
+// handed the same node again before SaveWindow clears the flag.
+func (n *Neo4jGraph) NextBatch(ctx context.Context, limit int) ([]string, error) {
+	session := n.writeSession()
+	defer session.Close()
+
+	result, err := session.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		result, err := tx.Run(`
+			MATCH (n:Repository)
+			WHERE n.last_targeted IS NULL AND n.in_flight IS NULL AND NOT n.full_name CONTAINS '.'
+			WITH n LIMIT $limit
+			SET n.in_flight = true
+			RETURN n.full_name AS full_name`,
+			map[string]interface{}{"limit": limit},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		var refs []string
+		for result.Next() {
+			fullName, _ := result.Record().Get("full_name")
+			refs = append(refs, fullName.(string))
+		}
+
+		return refs, result.Err()
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]string), nil
+}
+
+// Release clears the in-flight flag on refs without marking them
+// targeted, so a future NextBatch call can hand them out again.
+func (n *Neo4jGraph) Release(ctx context.Context, refs []string) error {
+	if len(refs) == 0 {
+		return nil
+	}
+
+	session := n.writeSession()
+	defer session.Close()
+
+	_, err := session.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		return tx.Run(`
+			MATCH (n:Repository) WHERE n.full_name IN $full_names
+			REMOVE n.in_flight`,
+			map[string]interface{}{"full_names": refs},
+		)
+	})
+
+	return err
+}
+
+// Neighbors returns the repositories directly connected to ref by a
+// DEPENDS_ON relationship in either direction.
+func (n *Neo4jGraph) Neighbors(ctx context.Context, ref string) ([]source.Repository, error) {
+	session := n.readSession()
+	defer session.Close()
+
+	result, err := session.ReadTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		result, err := tx.Run(`
+			MATCH (c:Repository {full_name: $full_name})-[:DEPENDS_ON]-(n:Repository)
+			RETURN DISTINCT n.full_name AS full_name`,
+			map[string]interface{}{"full_name": ref},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		var repos []source.Repository
+		for result.Next() {
+			fullName, _ := result.Record().Get("full_name")
+			repos = append(repos, source.Repository{FQN: fullName.(string)})
+		}
+
+		return repos, result.Err()
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]source.Repository), nil
+}
+
+// DependencyEdges returns every DEPENDS_ON relationship that carries a
+// declared requirement.
+func (n *Neo4jGraph) DependencyEdges(ctx context.Context) ([]DependencyEdge, error) {
+	session := n.readSession()
+	defer session.Close()
+
+	result, err := session.ReadTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		result, err := tx.Run(`
+			MATCH (c:Repository)-[rel:DEPENDS_ON]->(r:Repository)
+			WHERE rel.requirement IS NOT NULL AND rel.requirement <> ''
+			RETURN c.full_name AS from_name, r.full_name AS to_name, rel.requirement AS requirement, rel.package_manager AS package_manager, rel.is_repository AS is_repository`,
+			nil,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		var edges []DependencyEdge
+		for result.Next() {
+			rec := result.Record()
+			from, _ := rec.Get("from_name")
+			to, _ := rec.Get("to_name")
+			requirement, _ := rec.Get("requirement")
+			packageManager, _ := rec.Get("package_manager")
+			isRepository, _ := rec.Get("is_repository")
+
+			edge := DependencyEdge{From: from.(string), To: to.(string), Requirement: requirement.(string)}
+			if packageManager != nil {
+				edge.PackageManager = packageManager.(string)
+			}
+			if isRepository != nil {
+				edge.IsRepository = isRepository.(bool)
+			}
+			edges = append(edges, edge)
+		}
+
+		return edges, result.Err()
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]DependencyEdge), nil
+}
+
+// SetDependencyVersion records the latest known release for the
+// dependency node identified by fqn, and whether it's outdated.
+func (n *Neo4jGraph) SetDependencyVersion(ctx context.Context, fqn, latestVersion string, outdated bool) error {
+	session := n.writeSession()
+	defer session.Close()
+
+	_, err := session.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		return tx.Run(`
+			MERGE (r:Repository {full_name: $full_name})
+			SET r.latest_version = $latest_version, r.outdated = $outdated`,
+			map[string]interface{}{
+				"full_name":      fqn,
+				"latest_version": latestVersion,
+				"outdated":       outdated,
+			},
+		)
+	})
+
+	return err
+}
+
+// Query answers dependency/dependent/path/impact traversals via Cypher
+// variable-length and shortestPath patterns.
+func (n *Neo4jGraph) Query(ctx context.Context, q Query) (QueryResult, error) {
+	switch q.Kind {
+	case QueryDependencies:
+		nodes, edges, err := n.walk(ctx, "-[:DEPENDS_ON*1..%d]->", q.Ref, q.Depth, true)
+		return QueryResult{Nodes: nodes, Edges: edges}, err
+	case QueryDependents:
+		nodes, edges, err := n.walk(ctx, "<-[:DEPENDS_ON*1..%d]-", q.Ref, q.Depth, false)
+		return QueryResult{Nodes: nodes, Edges: edges}, err
+	case QueryImpact:
+		return n.impact(ctx, q.Ref)
+	case QueryPath:
+		path, err := n.shortestPath(ctx, q.From, q.To)
+		return QueryResult{Path: path}, err
+	default:
+		return QueryResult{}, fmt.Errorf("storage: unknown query kind %v", q.Kind)
+	}
+}
+
+// walk runs a variable-length DEPENDS_ON traversal from ref in the
+// direction encoded by patternFmt, which must contain exactly one %d for
+// the maximum hop count. depth <= 0 is treated as unlimited, capped at
+// 20 hops to keep the query bounded. Alongside the reached nodes, it
+// returns one edge per node along a shortest path back to ref (rather
+// than every node's full set of paths), so callers rendering a DOT graph
+// can draw the actual dependency chain instead of a star. forward
+// selects which end of each edge is the dependent: true if patternFmt
+// walks outward along DEPENDS_ON (ref depends on the nodes found), false
+// if it walks inward (the nodes found depend on ref).
+func (n *Neo4jGraph) walk(ctx context.Context, patternFmt, ref string, depth int, forward bool) ([]source.Repository, []Edge, error) {
+	if depth <= 0 {
+		depth = 20
+	}
+
+	pattern := fmt.Sprintf(patternFmt, depth)
+
+	session := n.readSession()
+	defer session.Close()
+
+	result, err := session.ReadTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		result, err := tx.Run(fmt.Sprintf(`
+			MATCH p = (c:Repository {full_name: $full_name})%s(n:Repository)
+			WITH n, p
+			ORDER BY length(p) ASC
+			WITH n, collect(p)[0] AS p
+			RETURN n.full_name AS full_name, [x IN nodes(p) | x.full_name] AS path_names`, pattern),
+			map[string]interface{}{"full_name": ref},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		var repos []source.Repository
+		seen := map[Edge]bool{}
+		var edges []Edge
+		for result.Next() {
+			rec := result.Record()
+			fullName, _ := rec.Get("full_name")
+			repos = append(repos, source.Repository{FQN: fullName.(string)})
+
+			pathNames, _ := rec.Get("path_names")
+			for _, e := range edgesAlongPath(pathNames.([]interface{}), forward) {
+				if seen[e] {
+					continue
+				}
+				seen[e] = true
+				edges = append(edges, e)
+			}
+		}
+
+		return walkResult{repos, edges}, result.Err()
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wr := result.(walkResult)
+	return wr.nodes, wr.edges, nil
+}
+
+// walkResult is the Go value a walk transaction resolves to.
+type walkResult struct {
+	nodes []source.Repository
+	edges []Edge
+}
+
+// edgesAlongPath turns the full_name list of nodes(p), in path order
+// from ref outward, into the DEPENDS_ON hops between them. forward
+// matches the direction convention described on walk.
+func edgesAlongPath(names []interface{}, forward bool) []Edge {
+	var edges []Edge
+	for i := 0; i+1 < len(names); i++ {
+		from, to := names[i].(string), names[i+1].(string)
+		if !forward {
+			from, to = to, from
+		}
+		edges = append(edges, Edge{From: from, To: to})
+	}
+
+	return edges
+}
+
+// impact returns ref's full transitive dependent closure, with the
+// number of dependents found at each depth, and one edge per node along
+// a shortest path back to ref.
+func (n *Neo4jGraph) impact(ctx context.Context, ref string) (QueryResult, error) {
+	session := n.readSession()
+	defer session.Close()
+
+	result, err := session.ReadTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		result, err := tx.Run(`
+			MATCH p = (c:Repository {full_name: $full_name})<-[:DEPENDS_ON*1..]-(n:Repository)
+			WITH n, p, length(p) AS len
+			ORDER BY len ASC
+			WITH n, collect(p)[0] AS p, min(len) AS depth
+			RETURN n.full_name AS full_name, depth, [x IN nodes(p) | x.full_name] AS path_names
+			ORDER BY depth`,
+			map[string]interface{}{"full_name": ref},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		qr := QueryResult{DepthCounts: map[int]int{}}
+		seen := map[Edge]bool{}
+		for result.Next() {
+			rec := result.Record()
+			fullName, _ := rec.Get("full_name")
+			depth, _ := rec.Get("depth")
+			pathNames, _ := rec.Get("path_names")
+
+			d := int(depth.(int64))
+			qr.Nodes = append(qr.Nodes, source.Repository{FQN: fullName.(string)})
+			qr.DepthCounts[d]++
+
+			for _, e := range edgesAlongPath(pathNames.([]interface{}), false) {
+				if seen[e] {
+					continue
+				}
+				seen[e] = true
+				qr.Edges = append(qr.Edges, e)
+			}
+		}
+
+		return qr, result.Err()
+	})
+	if err != nil {
+		return QueryResult{}, err
+	}
+
+	return result.(QueryResult), nil
+}
+
+// shortestPath finds the shortest DEPENDS_ON path, in either direction,
+// between from and to.
+func (n *Neo4jGraph) shortestPath(ctx context.Context, from, to string) ([]source.Repository, error) {
+	session := n.readSession()
+	defer session.Close()
+
+	result, err := session.ReadTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		result, err := tx.Run(`
+			MATCH (a:Repository {full_name: $from}), (b:Repository {full_name: $to}),
+				p = shortestPath((a)-[:DEPENDS_ON*]-(b))
+			RETURN [n IN nodes(p) | n.full_name] AS names`,
+			map[string]interface{}{"from": from, "to": to},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if !result.Next() {
+			return nil, result.Err()
+		}
+
+		names, _ := result.Record().Get("names")
+		var repos []source.Repository
+		for _, name := range names.([]interface{}) {
+			repos = append(repos, source.Repository{FQN: name.(string)})
+		}
+
+		return repos, result.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, nil
+	}
+
+	return result.([]source.Repository), nil
+}
+
+func (n *Neo4jGraph) Close() error {
+	return n.driver.Close()
+}
@@ -0,0 +1,383 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/trinitroglycerin/dependagraph/internal/source"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS repositories (
+	full_name      TEXT PRIMARY KEY,
+	last_targeted  INTEGER,
+	in_flight      INTEGER NOT NULL DEFAULT 0,
+	latest_version TEXT,
+	outdated       INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS edges (
+	from_repo       TEXT NOT NULL,
+	to_repo         TEXT NOT NULL,
+	requirement     TEXT NOT NULL DEFAULT '',
+	package_manager TEXT NOT NULL DEFAULT '',
+	is_repository   INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (from_repo, to_repo)
+);
+`
+
+// SQLiteGraph stores the dependency graph in a local SQLite database: a
+// repositories table for crawl state, and a from_repo/to_repo adjacency
+// table for DEPENDS_ON edges. It lets users try Dependagraph without
+// running a Neo4j instance.
+type SQLiteGraph struct {
+	db *sql.DB
+}
+
+// NewSQLiteGraph opens (creating if necessary) the SQLite database at dsn
+// and ensures its schema exists. dsn is a file path, or ":memory:" for a
+// throwaway in-process database; an empty dsn defaults to
+// "dependagraph.db" in the working directory.
+func NewSQLiteGraph(dsn string) (*SQLiteGraph, error) {
+	if dsn == "" {
+		dsn = "dependagraph.db"
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: opening %s: %w", dsn, err)
+	}
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sqlite: creating schema: %w", err)
+	}
+
+	return &SQLiteGraph{db: db}, nil
+}
+
+func (s *SQLiteGraph) SaveWindow(ctx context.Context, ref string, dependencies, dependents []source.Repository) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sqlite: beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO repositories (full_name, last_targeted, in_flight) VALUES (?, strftime('%s', 'now'), 0)
+		ON CONFLICT(full_name) DO UPDATE SET last_targeted = excluded.last_targeted, in_flight = 0`, ref); err != nil {
+		return fmt.Errorf("sqlite: recording %s as targeted: %w", ref, err)
+	}
+
+	for _, dep := range dependencies {
+		isRepository := dep.Organization != "" && dep.Repository != ""
+		if err := upsertEdge(ctx, tx, ref, dep.FQN, dep.Version, dep.PackageManager, isRepository); err != nil {
+			return err
+		}
+	}
+
+	for _, dep := range dependents {
+		if err := upsertEdge(ctx, tx, dep.FQN, ref, "", "", false); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func upsertEdge(ctx context.Context, tx *sql.Tx, from, to, requirement, packageManager string, isRepository bool) error {
+	if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO repositories (full_name) VALUES (?)`, from); err != nil {
+		return fmt.Errorf("sqlite: upserting %s: %w", from, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO repositories (full_name) VALUES (?)`, to); err != nil {
+		return fmt.Errorf("sqlite: upserting %s: %w", to, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO edges (from_repo, to_repo, requirement, package_manager, is_repository) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(from_repo, to_repo) DO UPDATE SET
+			requirement = CASE excluded.requirement WHEN '' THEN edges.requirement ELSE excluded.requirement END,
+			package_manager = CASE excluded.package_manager WHEN '' THEN edges.package_manager ELSE excluded.package_manager END,
+			is_repository = is_repository OR excluded.is_repository`,
+		from, to, requirement, packageManager, isRepository); err != nil {
+		return fmt.Errorf("sqlite: linking %s -> %s: %w", from, to, err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteGraph) NextBatch(ctx context.Context, n int) ([]string, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT full_name FROM repositories
+		WHERE last_targeted IS NULL AND in_flight = 0 AND full_name NOT LIKE '%.%'
+		LIMIT ?`, n)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: selecting next batch: %w", err)
+	}
+
+	var refs []string
+	for rows.Next() {
+		var ref string
+		if err := rows.Scan(&ref); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, err
+	}
+	rows.Close()
+
+	for _, ref := range refs {
+		if _, err := tx.ExecContext(ctx, `UPDATE repositories SET in_flight = 1 WHERE full_name = ?`, ref); err != nil {
+			return nil, fmt.Errorf("sqlite: marking %s in-flight: %w", ref, err)
+		}
+	}
+
+	return refs, tx.Commit()
+}
+
+// Release clears the in-flight flag on refs without marking them
+// targeted, so a future NextBatch call can hand them out again.
+func (s *SQLiteGraph) Release(ctx context.Context, refs []string) error {
+	for _, ref := range refs {
+		if _, err := s.db.ExecContext(ctx, `UPDATE repositories SET in_flight = 0 WHERE full_name = ?`, ref); err != nil {
+			return fmt.Errorf("sqlite: releasing %s: %w", ref, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLiteGraph) Neighbors(ctx context.Context, ref string) ([]source.Repository, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT to_repo FROM edges WHERE from_repo = ?
+		UNION
+		SELECT from_repo FROM edges WHERE to_repo = ?`, ref, ref)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: selecting neighbors of %s: %w", ref, err)
+	}
+	defer rows.Close()
+
+	var repos []source.Repository
+	for rows.Next() {
+		var fqn string
+		if err := rows.Scan(&fqn); err != nil {
+			return nil, err
+		}
+		repos = append(repos, source.Repository{FQN: fqn})
+	}
+
+	return repos, rows.Err()
+}
+
+// DependencyEdges returns every edge that carries a declared requirement.
+func (s *SQLiteGraph) DependencyEdges(ctx context.Context) ([]DependencyEdge, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT from_repo, to_repo, requirement, package_manager, is_repository FROM edges
+		WHERE requirement != ''`)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: selecting dependency edges: %w", err)
+	}
+	defer rows.Close()
+
+	var edges []DependencyEdge
+	for rows.Next() {
+		var edge DependencyEdge
+		if err := rows.Scan(&edge.From, &edge.To, &edge.Requirement, &edge.PackageManager, &edge.IsRepository); err != nil {
+			return nil, err
+		}
+		edges = append(edges, edge)
+	}
+
+	return edges, rows.Err()
+}
+
+// SetDependencyVersion records the latest known release for the
+// dependency node identified by fqn, and whether it's outdated.
+func (s *SQLiteGraph) SetDependencyVersion(ctx context.Context, fqn, latestVersion string, outdated bool) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO repositories (full_name, latest_version, outdated) VALUES (?, ?, ?)
+		ON CONFLICT(full_name) DO UPDATE SET latest_version = excluded.latest_version, outdated = excluded.outdated`,
+		fqn, latestVersion, outdated)
+	if err != nil {
+		return fmt.Errorf("sqlite: setting version for %s: %w", fqn, err)
+	}
+
+	return nil
+}
+
+// Query answers dependency/dependent/path/impact traversals over the
+// edges table.
+func (s *SQLiteGraph) Query(ctx context.Context, q Query) (QueryResult, error) {
+	switch q.Kind {
+	case QueryDependencies:
+		nodes, _, parent, err := s.bfs(ctx, "from_repo", "to_repo", q.Ref, q.Depth)
+		return QueryResult{Nodes: nodes, Edges: edgesFromParent(parent, true)}, err
+	case QueryDependents:
+		nodes, _, parent, err := s.bfs(ctx, "to_repo", "from_repo", q.Ref, q.Depth)
+		return QueryResult{Nodes: nodes, Edges: edgesFromParent(parent, false)}, err
+	case QueryImpact:
+		nodes, counts, parent, err := s.bfs(ctx, "to_repo", "from_repo", q.Ref, 0)
+		return QueryResult{Nodes: nodes, DepthCounts: counts, Edges: edgesFromParent(parent, false)}, err
+	case QueryPath:
+		path, err := s.shortestPath(ctx, q.From, q.To)
+		return QueryResult{Path: path}, err
+	default:
+		return QueryResult{}, fmt.Errorf("storage: unknown query kind %v", q.Kind)
+	}
+}
+
+// bfs walks the edges table from start, matching matchCol against the
+// current frontier and returning distinct values of stepCol, up to
+// maxDepth hops (0 means unlimited). It returns every node reached, how
+// many were found at each hop count, and a parent map (child -> the
+// node it was reached from) that callers use to reconstruct the
+// shortest-path edges back to start.
+func (s *SQLiteGraph) bfs(ctx context.Context, matchCol, stepCol, start string, maxDepth int) ([]source.Repository, map[int]int, map[string]string, error) {
+	visited := map[string]bool{start: true}
+	counts := map[int]int{}
+	parent := map[string]string{}
+	var nodes []source.Repository
+
+	frontier := []string{start}
+	for depth := 1; len(frontier) > 0 && (maxDepth == 0 || depth <= maxDepth); depth++ {
+		next, err := s.stepEdges(ctx, matchCol, stepCol, frontier)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		var fresh []string
+		for _, e := range next {
+			if visited[e.from] {
+				continue
+			}
+			visited[e.from] = true
+			parent[e.from] = e.via
+			nodes = append(nodes, source.Repository{FQN: e.from})
+			counts[depth]++
+			fresh = append(fresh, e.from)
+		}
+		frontier = fresh
+	}
+
+	return nodes, counts, parent, nil
+}
+
+// stepEdges returns, for every edge whose matchCol is one of refs, the
+// distinct (stepCol, matchCol) pair reached, so the caller can track
+// which frontier member led to each new node.
+func (s *SQLiteGraph) stepEdges(ctx context.Context, matchCol, stepCol string, refs []string) ([]sqliteEdge, error) {
+	placeholders := make([]string, len(refs))
+	args := make([]interface{}, len(refs))
+	for i, ref := range refs {
+		placeholders[i] = "?"
+		args[i] = ref
+	}
+
+	query := fmt.Sprintf(`SELECT DISTINCT %s, %s FROM edges WHERE %s IN (%s)`, stepCol, matchCol, matchCol, strings.Join(placeholders, ","))
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: stepping edges: %w", err)
+	}
+	defer rows.Close()
+
+	var out []sqliteEdge
+	for rows.Next() {
+		var e sqliteEdge
+		if err := rows.Scan(&e.from, &e.via); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+
+	return out, rows.Err()
+}
+
+// shortestPath finds the shortest undirected path from from to to via
+// breadth-first search over the edges table, returning nil if they
+// aren't connected.
+func (s *SQLiteGraph) shortestPath(ctx context.Context, from, to string) ([]source.Repository, error) {
+	if from == to {
+		return []source.Repository{{FQN: from}}, nil
+	}
+
+	visited := map[string]bool{from: true}
+	parent := map[string]string{}
+	frontier := []string{from}
+
+	for len(frontier) > 0 {
+		neighbors, err := s.undirectedNeighbors(ctx, frontier)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []string
+		for _, n := range neighbors {
+			if visited[n.from] {
+				continue
+			}
+			visited[n.from] = true
+			parent[n.from] = n.via
+			if n.from == to {
+				return buildPath(parent, from, to), nil
+			}
+			next = append(next, n.from)
+		}
+		frontier = next
+	}
+
+	return nil, nil
+}
+
+type sqliteEdge struct{ from, via string }
+
+// undirectedNeighbors returns every ref directly connected to any of
+// frontier, in either edge direction, alongside which frontier member it
+// was reached from.
+func (s *SQLiteGraph) undirectedNeighbors(ctx context.Context, frontier []string) ([]sqliteEdge, error) {
+	placeholders := make([]string, len(frontier))
+	args := make([]interface{}, len(frontier))
+	for i, ref := range frontier {
+		placeholders[i] = "?"
+		args[i] = ref
+	}
+	in := strings.Join(placeholders, ",")
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT to_repo, from_repo FROM edges WHERE from_repo IN (%s)
+		UNION
+		SELECT from_repo, to_repo FROM edges WHERE to_repo IN (%s)`, in, in),
+		append(append([]interface{}{}, args...), args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: finding neighbors: %w", err)
+	}
+	defer rows.Close()
+
+	var out []sqliteEdge
+	for rows.Next() {
+		var e sqliteEdge
+		if err := rows.Scan(&e.from, &e.via); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+
+	return out, rows.Err()
+}
+
+func (s *SQLiteGraph) Close() error {
+	return s.db.Close()
+}
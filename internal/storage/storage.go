@@ -0,0 +1,164 @@
+// Package storage implements Dependagraph's pluggable graph persistence
+// backends. Neo4j is the primary target; SQLite and an in-memory store
+// let users try the crawler, or write tests, without standing one up.
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/trinitroglycerin/dependagraph/internal/source"
+)
+
+// Graph is the persistence interface the crawler (and any future query
+// layer) depends on. Every storage backend implements it independently.
+type Graph interface {
+	// SaveWindow persists ref's crawled dependency window: it records
+	// that ref was targeted, and links it to its dependencies and
+	// dependents.
+	SaveWindow(ctx context.Context, ref string, dependencies, dependents []source.Repository) error
+	// NextBatch returns up to n untargeted repository refs, marking them
+	// in-flight so a concurrent caller doesn't receive the same ref
+	// twice before SaveWindow clears the flag.
+	NextBatch(ctx context.Context, n int) ([]string, error)
+	// Release clears the in-flight flag NextBatch set on refs without
+	// marking them targeted, so they're handed out again by a future
+	// NextBatch call. Callers use this to give back refs a NextBatch
+	// call claimed but never actually dispatched to a worker, e.g. on
+	// shutdown.
+	Release(ctx context.Context, refs []string) error
+	// Neighbors returns the repositories ref directly depends upon or is
+	// depended upon by.
+	Neighbors(ctx context.Context, ref string) ([]source.Repository, error)
+	// DependencyEdges returns every crawled dependency relationship that
+	// carries a declared requirement, for VersionResolver to check against
+	// each dependency's latest known release.
+	DependencyEdges(ctx context.Context) ([]DependencyEdge, error)
+	// SetDependencyVersion records the latest known release for a
+	// dependency node and whether it's outdated relative to the
+	// requirement(s) that depend on it.
+	SetDependencyVersion(ctx context.Context, fqn, latestVersion string, outdated bool) error
+	// Query answers the traversals the HTTP query API exposes: bounded
+	// dependency/dependent walks, shortest path between two refs, and a
+	// dependent's transitive impact closure.
+	Query(ctx context.Context, q Query) (QueryResult, error)
+	Close() error
+}
+
+// QueryKind selects the traversal a Query performs.
+type QueryKind int
+
+const (
+	// QueryDependencies walks outward along DEPENDS_ON from Ref, up to
+	// Depth hops (0 means unlimited; the Neo4j backend caps it at 20).
+	QueryDependencies QueryKind = iota
+	// QueryDependents walks inward along DEPENDS_ON into Ref, up to
+	// Depth hops (0 means unlimited; the Neo4j backend caps it at 20).
+	QueryDependents
+	// QueryPath finds the shortest DEPENDS_ON path, in either direction,
+	// between From and To.
+	QueryPath
+	// QueryImpact returns Ref's full transitive dependent closure, with
+	// the count of nodes found at each depth.
+	QueryImpact
+)
+
+// Query describes one read against the graph. Which fields are used
+// depends on Kind.
+type Query struct {
+	Kind     QueryKind
+	Ref      string
+	From, To string
+	Depth    int
+}
+
+// QueryResult holds the traversal's answer. Which fields are populated
+// depends on the Query.Kind that produced it.
+type QueryResult struct {
+	// Nodes holds the result set for QueryDependencies, QueryDependents
+	// and QueryImpact.
+	Nodes []source.Repository
+	// Edges holds, for QueryDependencies, QueryDependents and
+	// QueryImpact, one DEPENDS_ON hop per node in Nodes along a shortest
+	// path back to Ref, always pointing from dependent to dependency.
+	// Rendering these rather than a star from Ref to every node in Nodes
+	// is what lets the DOT output show the actual dependency chain.
+	Edges []Edge
+	// Path holds the ordered path found by QueryPath, empty if none
+	// exists.
+	Path []source.Repository
+	// DepthCounts maps hop count -> number of dependents found at that
+	// depth, for QueryImpact.
+	DepthCounts map[int]int
+}
+
+// Edge is one directed DEPENDS_ON hop within a QueryResult's Edges,
+// always pointing from dependent to dependency.
+type Edge struct {
+	From, To string
+}
+
+// edgesFromParent turns a BFS parent map (child -> the node it was
+// reached from) into Edges. forward selects which end is the dependent:
+// true for a dependency walk (parent depends on child), false for a
+// dependent/impact walk (child depends on parent).
+func edgesFromParent(parent map[string]string, forward bool) []Edge {
+	edges := make([]Edge, 0, len(parent))
+	for child, par := range parent {
+		if forward {
+			edges = append(edges, Edge{From: par, To: child})
+		} else {
+			edges = append(edges, Edge{From: child, To: par})
+		}
+	}
+
+	return edges
+}
+
+// DependencyEdge is one crawled DEPENDS_ON relationship, carrying the
+// requirement the dependent declared on the dependency.
+type DependencyEdge struct {
+	From, To       string
+	Requirement    string
+	PackageManager string
+	// IsRepository reports whether To was resolved to a known git-hosted
+	// repository (as opposed to a bare package name the source couldn't
+	// resolve further), which VersionResolver uses to prefer the Releases
+	// API over guessing an ecosystem from PackageManager.
+	IsRepository bool
+}
+
+// Kind identifies a supported storage backend.
+type Kind string
+
+const (
+	KindNeo4j  Kind = "neo4j"
+	KindSQLite Kind = "sqlite"
+	KindMemory Kind = "memory"
+)
+
+// Config carries the connection details needed to construct a Graph. Each
+// backend's constructor picks out only the fields it needs.
+type Config struct {
+	// URI is the neo4j connection URI (e.g. "bolt://localhost:7687").
+	URI      string
+	User     string
+	Password string
+	// DSN is the sqlite data source name: a file path, or ":memory:" for
+	// a throwaway in-process database.
+	DSN string
+}
+
+// New constructs the Graph for the given storage Kind.
+func New(kind Kind, cfg Config) (Graph, error) {
+	switch kind {
+	case KindNeo4j, "":
+		return NewNeo4jGraph(cfg.URI, cfg.User, cfg.Password)
+	case KindSQLite:
+		return NewSQLiteGraph(cfg.DSN)
+	case KindMemory:
+		return NewMemoryGraph(), nil
+	default:
+		return nil, fmt.Errorf("storage: unknown backend kind %q", kind)
+	}
+}
@@ -0,0 +1,67 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/trinitroglycerin/dependagraph/internal/storage"
+)
+
+// OutdatedDependency is one crawled dependency whose declared requirement
+// no longer permits its latest known release.
+type OutdatedDependency struct {
+	From, To      string
+	Requirement   string
+	LatestVersion string
+	Ecosystem     Ecosystem
+}
+
+// Report resolves the latest release for every dependency edge graph
+// knows about, records it (and whether it's outdated) on the dependency
+// node, and returns the ones found to be outdated. Edges whose ecosystem
+// can't be determined are skipped silently; edges whose registry lookup or
+// version comparison fails are logged and skipped rather than failing the
+// whole report.
+func Report(ctx context.Context, graph storage.Graph, resolver Resolver) ([]OutdatedDependency, error) {
+	edges, err := graph.DependencyEdges(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("version: listing dependency edges: %w", err)
+	}
+
+	var outdated []OutdatedDependency
+	for _, edge := range edges {
+		eco := EcosystemFor(edge.PackageManager, edge.IsRepository)
+		if eco == "" {
+			continue
+		}
+
+		latest, err := resolver.Resolve(ctx, eco, edge.To)
+		if err != nil {
+			log.Printf("version: skipping %s: %s", edge.To, err)
+			continue
+		}
+
+		isOutdated, err := Outdated(edge.Requirement, latest)
+		if err != nil {
+			log.Printf("version: skipping %s: %s", edge.To, err)
+			continue
+		}
+
+		if err := graph.SetDependencyVersion(ctx, edge.To, latest, isOutdated); err != nil {
+			return nil, fmt.Errorf("version: recording version for %s: %w", edge.To, err)
+		}
+
+		if isOutdated {
+			outdated = append(outdated, OutdatedDependency{
+				From:          edge.From,
+				To:            edge.To,
+				Requirement:   edge.Requirement,
+				LatestVersion: latest,
+				Ecosystem:     eco,
+			})
+		}
+	}
+
+	return outdated, nil
+}
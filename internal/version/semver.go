@@ -0,0 +1,30 @@
+package version
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Outdated reports whether latest is not allowed by requirement (a
+// semver constraint using the usual ~/^/>=/... operators), meaning a
+// release exists beyond what the dependency's declared requirement
+// permits. If requirement isn't a valid constraint, it's treated as an
+// exact pin instead, and outdated is true whenever latest is newer than it.
+func Outdated(requirement, latest string) (bool, error) {
+	latestVersion, err := semver.NewVersion(latest)
+	if err != nil {
+		return false, fmt.Errorf("version: parsing latest version %q: %w", latest, err)
+	}
+
+	constraint, err := semver.NewConstraint(requirement)
+	if err != nil {
+		pinned, pinErr := semver.NewVersion(requirement)
+		if pinErr != nil {
+			return false, fmt.Errorf("version: parsing requirement %q: %w", requirement, err)
+		}
+		return pinned.LessThan(latestVersion), nil
+	}
+
+	return !constraint.Check(latestVersion), nil
+}
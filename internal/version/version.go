@@ -0,0 +1,166 @@
+// Package version resolves the latest known release for a crawled
+// dependency from its ecosystem's registry, and decides whether the
+// requirement a repository declared on it is behind that release.
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// Ecosystem identifies the registry used to resolve a dependency's latest
+// release.
+type Ecosystem string
+
+const (
+	// EcosystemGit resolves via the GitHub Releases API, for
+	// dependencies the dependency graph resolved to a known repository.
+	EcosystemGit  Ecosystem = "git"
+	EcosystemGo   Ecosystem = "go"
+	EcosystemNPM  Ecosystem = "npm"
+	EcosystemPyPI Ecosystem = "pypi"
+)
+
+// EcosystemFor maps a forge-reported package manager label to the
+// Ecosystem used to resolve its latest release. hasRepository indicates
+// the dependency graph resolved this dependency to a known git-hosted
+// repository, which takes priority since it gives an exact Releases API
+// to query regardless of what packageManager says.
+func EcosystemFor(packageManager string, hasRepository bool) Ecosystem {
+	if hasRepository {
+		return EcosystemGit
+	}
+
+	switch strings.ToUpper(packageManager) {
+	case "GO", "GOMOD":
+		return EcosystemGo
+	case "NPM", "YARN":
+		return EcosystemNPM
+	case "PIP", "POETRY", "PYPI":
+		return EcosystemPyPI
+	default:
+		return ""
+	}
+}
+
+// Resolver resolves the latest known release for a dependency in the
+// given ecosystem.
+type Resolver interface {
+	Resolve(ctx context.Context, eco Ecosystem, pkg string) (latest string, err error)
+}
+
+// HTTPResolver resolves latest releases from each ecosystem's public
+// registry: GitHub Releases, proxy.golang.org, registry.npmjs.org and
+// pypi.org.
+type HTTPResolver struct {
+	httpClient *http.Client
+}
+
+// NewHTTPResolver returns an HTTPResolver using http.DefaultClient.
+func NewHTTPResolver() *HTTPResolver {
+	return &HTTPResolver{httpClient: http.DefaultClient}
+}
+
+func (r *HTTPResolver) Resolve(ctx context.Context, eco Ecosystem, pkg string) (string, error) {
+	switch eco {
+	case EcosystemGit:
+		return r.resolveGit(ctx, pkg)
+	case EcosystemGo:
+		return r.resolveGo(ctx, pkg)
+	case EcosystemNPM:
+		return r.resolveNPM(ctx, pkg)
+	case EcosystemPyPI:
+		return r.resolvePyPI(ctx, pkg)
+	default:
+		return "", fmt.Errorf("version: unknown ecosystem %q for %s", eco, pkg)
+	}
+}
+
+func (r *HTTPResolver) resolveGit(ctx context.Context, nameWithOwner string) (string, error) {
+	var resp struct {
+		TagName string `json:"tag_name"`
+	}
+
+	url := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", nameWithOwner)
+	if err := r.getJSON(ctx, url, &resp); err != nil {
+		return "", fmt.Errorf("version: resolving github release for %s: %w", nameWithOwner, err)
+	}
+
+	return resp.TagName, nil
+}
+
+func (r *HTTPResolver) resolveGo(ctx context.Context, mod string) (string, error) {
+	var resp struct {
+		Version string
+	}
+
+	// The goproxy protocol escapes uppercase letters as "!"+lowercase per
+	// path element rather than lowercasing them outright, so that e.g.
+	// github.com/Masterminds/semver and a hypothetical
+	// github.com/masterminds/semver don't collide.
+	escaped, err := module.EscapePath(mod)
+	if err != nil {
+		return "", fmt.Errorf("version: invalid go module path %s: %w", mod, err)
+	}
+
+	url := fmt.Sprintf("https://proxy.golang.org/%s/@latest", escaped)
+	if err := r.getJSON(ctx, url, &resp); err != nil {
+		return "", fmt.Errorf("version: resolving go module %s: %w", mod, err)
+	}
+
+	return resp.Version, nil
+}
+
+func (r *HTTPResolver) resolveNPM(ctx context.Context, pkg string) (string, error) {
+	var resp struct {
+		DistTags struct {
+			Latest string `json:"latest"`
+		} `json:"dist-tags"`
+	}
+
+	url := fmt.Sprintf("https://registry.npmjs.org/%s", pkg)
+	if err := r.getJSON(ctx, url, &resp); err != nil {
+		return "", fmt.Errorf("version: resolving npm package %s: %w", pkg, err)
+	}
+
+	return resp.DistTags.Latest, nil
+}
+
+func (r *HTTPResolver) resolvePyPI(ctx context.Context, pkg string) (string, error) {
+	var resp struct {
+		Info struct {
+			Version string `json:"version"`
+		} `json:"info"`
+	}
+
+	url := fmt.Sprintf("https://pypi.org/pypi/%s/json", pkg)
+	if err := r.getJSON(ctx, url, &resp); err != nil {
+		return "", fmt.Errorf("version: resolving pypi package %s: %w", pkg, err)
+	}
+
+	return resp.Info.Version, nil
+}
+
+func (r *HTTPResolver) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
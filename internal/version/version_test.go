@@ -0,0 +1,61 @@
+package version
+
+import "testing"
+
+func TestOutdated(t *testing.T) {
+	tests := []struct {
+		name        string
+		requirement string
+		latest      string
+		want        bool
+		wantErr     bool
+	}{
+		{"constraint satisfied", "^1.2.0", "1.2.5", false, false},
+		{"constraint exceeded", "^1.2.0", "2.0.0", true, false},
+		{"tilde satisfied", "~1.2.0", "1.2.9", false, false},
+		{"tilde exceeded", "~1.2.0", "1.3.0", true, false},
+		{"exact pin matches", "1.2.0", "1.2.0", false, false},
+		{"exact pin behind", "1.2.0", "1.3.0", true, false},
+		{"invalid latest", "^1.0.0", "not-a-version", false, true},
+		{"invalid requirement and pin", "not-a-version", "1.0.0", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Outdated(tt.requirement, tt.latest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Outdated(%q, %q) error = %v, wantErr %v", tt.requirement, tt.latest, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("Outdated(%q, %q) = %v, want %v", tt.requirement, tt.latest, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEcosystemFor(t *testing.T) {
+	tests := []struct {
+		name           string
+		packageManager string
+		hasRepository  bool
+		want           Ecosystem
+	}{
+		{"repository takes priority", "NPM", true, EcosystemGit},
+		{"go", "GO", false, EcosystemGo},
+		{"gomod", "gomod", false, EcosystemGo},
+		{"npm", "NPM", false, EcosystemNPM},
+		{"yarn", "yarn", false, EcosystemNPM},
+		{"pip", "pip", false, EcosystemPyPI},
+		{"poetry", "POETRY", false, EcosystemPyPI},
+		{"unknown", "cargo", false, ""},
+		{"empty", "", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EcosystemFor(tt.packageManager, tt.hasRepository); got != tt.want {
+				t.Errorf("EcosystemFor(%q, %v) = %q, want %q", tt.packageManager, tt.hasRepository, got, tt.want)
+			}
+		})
+	}
+}
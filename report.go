@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/trinitroglycerin/dependagraph/internal/config"
+	"github.com/trinitroglycerin/dependagraph/internal/storage"
+	"github.com/trinitroglycerin/dependagraph/internal/version"
+)
+
+// runReport implements the "dependagraph report" subcommand, which reads
+// an already-crawled graph rather than crawling one itself.
+func runReport(args []string) {
+	opts, outdated, err := config.LoadForReport(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !outdated {
+		fmt.Fprintln(os.Stderr, "usage: dependagraph report --outdated")
+		os.Exit(2)
+	}
+
+	graph, err := storage.New(storage.Kind(opts.Storage.Backend), storage.Config{
+		URI:      opts.Storage.URI,
+		User:     opts.Storage.User,
+		Password: opts.Storage.Password,
+		DSN:      opts.Storage.DSN,
+	})
+	if err != nil {
+		log.Fatalf("invalid storage: %s", err)
+	}
+	defer graph.Close()
+
+	results, err := version.Report(context.Background(), graph, version.NewHTTPResolver())
+	if err != nil {
+		log.Fatalf("report: %s", err)
+	}
+
+	printOutdatedByEcosystem(results)
+}
+
+func printOutdatedByEcosystem(results []version.OutdatedDependency) {
+	byEcosystem := map[version.Ecosystem][]version.OutdatedDependency{}
+	for _, r := range results {
+		byEcosystem[r.Ecosystem] = append(byEcosystem[r.Ecosystem], r)
+	}
+
+	ecosystems := make([]string, 0, len(byEcosystem))
+	for eco := range byEcosystem {
+		ecosystems = append(ecosystems, string(eco))
+	}
+	sort.Strings(ecosystems)
+
+	for _, eco := range ecosystems {
+		fmt.Printf("%s:\n", eco)
+		for _, r := range byEcosystem[version.Ecosystem(eco)] {
+			fmt.Printf("  %s depends on %s %s, latest is %s\n", r.From, r.To, r.Requirement, r.LatestVersion)
+		}
+	}
+}